@@ -0,0 +1,75 @@
+package solana
+
+// Associated Token program instruction discriminators.
+const (
+	associatedTokenCreate           = 0
+	associatedTokenCreateIdempotent = 1
+)
+
+// CreateAssociatedTokenAccount creates the associated token account of a wallet for a mint.
+type CreateAssociatedTokenAccount struct {
+	Payer             PublicKey
+	AssociatedAccount PublicKey
+	Wallet            PublicKey
+	Mint              PublicKey
+	TokenProgram      PublicKey
+	Idempotent        bool // the instruction does not fail if the account already exists
+}
+
+// ProgramID implements DecodedInstruction.
+func (*CreateAssociatedTokenAccount) ProgramID() PublicKey { return AssociatedTokenProgramID }
+
+// Name implements DecodedInstruction.
+func (i *CreateAssociatedTokenAccount) Name() string {
+	if i.Idempotent {
+		return "CreateIdempotent"
+	}
+	return "Create"
+}
+
+// Instruction returns the instruction. The SPL Token program is used unless TokenProgram is set.
+func (i *CreateAssociatedTokenAccount) Instruction() Instruction {
+	kind := byte(associatedTokenCreate)
+	if i.Idempotent {
+		kind = associatedTokenCreateIdempotent
+	}
+	return Instruction{
+		ProgramID: AssociatedTokenProgramID,
+		Accounts: []AccountMeta{
+			Meta(i.Payer, true, true),
+			Meta(i.AssociatedAccount, false, true),
+			Meta(i.Wallet, false, false),
+			Meta(i.Mint, false, false),
+			Meta(SystemProgramID, false, false),
+			Meta(tokenProgram(i.TokenProgram), false, false),
+		},
+		Data: []byte{kind},
+	}
+}
+
+func decodeAssociatedToken(ix Instruction) (DecodedInstruction, error) {
+	// An empty instruction data is the legacy encoding of Create.
+	kind := byte(associatedTokenCreate)
+	if len(ix.Data) > 0 {
+		kind = ix.Data[0]
+	}
+	if kind != associatedTokenCreate && kind != associatedTokenCreateIdempotent {
+		return nil, unknownInstruction("associated token", kind)
+	}
+
+	a := &accountsReader{ix: ix}
+	i := &CreateAssociatedTokenAccount{
+		Payer:             a.next(),
+		AssociatedAccount: a.next(),
+		Wallet:            a.next(),
+		Mint:              a.next(),
+		Idempotent:        kind == associatedTokenCreateIdempotent,
+	}
+	a.next() // system program
+	i.TokenProgram = a.next()
+	if a.err != nil {
+		return nil, invalidData(i.Name(), a.err)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,94 @@
+package solana
+
+import (
+	"fmt"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet used by Solana.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Index maps an alphabet character to its value, -1 for invalid characters.
+var base58Index = func() [256]int8 {
+	var idx [256]int8
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		idx[base58Alphabet[i]] = int8(i)
+	}
+	return idx
+}()
+
+// EncodeBase58 encodes b to a base58 string.
+func EncodeBase58(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58) ~ 1.37, rounded up.
+	buf := make([]byte, (len(b)-zeros)*138/100+1)
+	high := len(buf) - 1
+	for _, c := range b[zeros:] {
+		carry := int(c)
+		j := len(buf) - 1
+		for ; j > high || carry != 0; j-- {
+			carry += 256 * int(buf[j])
+			buf[j] = byte(carry % 58)
+			carry /= 58
+		}
+		high = j
+	}
+
+	start := 0
+	for start < len(buf) && buf[start] == 0 {
+		start++
+	}
+
+	out := make([]byte, zeros+len(buf)-start)
+	for i := 0; i < zeros; i++ {
+		out[i] = '1'
+	}
+	for i, v := range buf[start:] {
+		out[zeros+i] = base58Alphabet[v]
+	}
+
+	return string(out)
+}
+
+// DecodeBase58 decodes a base58 string.
+func DecodeBase58(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	// log(58) / log(256) ~ 0.733, rounded up.
+	buf := make([]byte, (len(s)-zeros)*733/1000+1)
+	high := len(buf) - 1
+	for i := zeros; i < len(s); i++ {
+		v := base58Index[s[i]]
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at position %d", s[i], i)
+		}
+
+		carry := int(v)
+		j := len(buf) - 1
+		for ; j > high || carry != 0; j-- {
+			carry += 58 * int(buf[j])
+			buf[j] = byte(carry % 256)
+			carry /= 256
+		}
+		high = j
+	}
+
+	start := 0
+	for start < len(buf) && buf[start] == 0 {
+		start++
+	}
+
+	out := make([]byte, zeros+len(buf)-start)
+	copy(out[zeros:], buf[start:])
+
+	return out, nil
+}
@@ -0,0 +1,131 @@
+package solana
+
+import (
+	"fmt"
+)
+
+// MaxTransactionSize is the maximum size of a serialized transaction, the size of an IPv6 packet payload.
+const MaxTransactionSize = 1232
+
+// maxAccounts is the maximum number of accounts a message can reference, indexes being a single byte.
+const maxAccounts = 256
+
+// compiledKey tracks how a key is used by the instructions of a message.
+type compiledKey struct {
+	key        PublicKey
+	isSigner   bool
+	isWritable bool
+	isInvoked  bool // used as a program id
+}
+
+// NewMessageV0 compiles the instructions into a v0 message paid by payer.
+//
+// Accounts are ordered as required by the runtime: the payer first, then writable signers,
+// read-only signers, writable and read-only non-signers, each group in order of first use.
+// Accounts found in one of the given lookup tables are loaded from the table instead of
+// being stored in the message, unless they are signers or invoked programs.
+func NewMessageV0(payer PublicKey, instructions []Instruction, recentBlockhash Hash, tables []AddressLookupTable) (*Message, error) {
+	keys := []*compiledKey{{key: payer, isSigner: true, isWritable: true}}
+	index := map[PublicKey]*compiledKey{payer: keys[0]}
+	use := func(key PublicKey) *compiledKey {
+		k, ok := index[key]
+		if !ok {
+			k = &compiledKey{key: key}
+			index[key] = k
+			keys = append(keys, k)
+		}
+		return k
+	}
+
+	for _, ix := range instructions {
+		use(ix.ProgramID).isInvoked = true
+		for _, a := range ix.Accounts {
+			k := use(a.PublicKey)
+			k.isSigner = k.isSigner || a.IsSigner
+			k.isWritable = k.isWritable || a.IsWritable
+		}
+	}
+
+	// Move lookup eligible keys to the first table containing them.
+	loaded := make(map[PublicKey]bool)
+	var lookups []MessageAddressTableLookup
+	var loadedWritable, loadedReadonly []PublicKey
+	for _, table := range tables {
+		lookup := MessageAddressTableLookup{AccountKey: table.Key}
+		var writable, readonly []PublicKey
+		for i, addr := range table.Addresses {
+			if i >= maxAccounts {
+				break
+			}
+			k, ok := index[addr]
+			if !ok || loaded[addr] || k.isSigner || k.isInvoked {
+				continue
+			}
+			loaded[addr] = true
+			if k.isWritable {
+				lookup.WritableIndexes = append(lookup.WritableIndexes, uint8(i))
+				writable = append(writable, addr)
+			} else {
+				lookup.ReadonlyIndexes = append(lookup.ReadonlyIndexes, uint8(i))
+				readonly = append(readonly, addr)
+			}
+		}
+		if len(writable)+len(readonly) == 0 {
+			continue
+		}
+		lookups = append(lookups, lookup)
+		loadedWritable = append(loadedWritable, writable...)
+		loadedReadonly = append(loadedReadonly, readonly...)
+	}
+
+	msg := &Message{Version: MessageVersionV0, RecentBlockhash: recentBlockhash, AddressTableLookups: lookups}
+	for _, group := range []struct{ signer, writable bool }{{true, true}, {true, false}, {false, true}, {false, false}} {
+		for _, k := range keys {
+			if loaded[k.key] || k.isSigner != group.signer || k.isWritable != group.writable {
+				continue
+			}
+			msg.AccountKeys = append(msg.AccountKeys, k.key)
+			switch {
+			case k.isSigner && !k.isWritable:
+				msg.Header.NumReadonlySignedAccounts++
+			case !k.isSigner && !k.isWritable:
+				msg.Header.NumReadonlyUnsignedAccounts++
+			}
+			if k.isSigner {
+				msg.Header.NumRequiredSignatures++
+			}
+		}
+	}
+
+	accounts := make([]PublicKey, 0, len(msg.AccountKeys)+len(loadedWritable)+len(loadedReadonly))
+	accounts = append(append(append(accounts, msg.AccountKeys...), loadedWritable...), loadedReadonly...)
+	if len(accounts) > maxAccounts {
+		return nil, fmt.Errorf("message references %d accounts, at most %d are allowed", len(accounts), maxAccounts)
+	}
+	positions := make(map[PublicKey]uint8, len(accounts))
+	for i, k := range accounts {
+		positions[k] = uint8(i)
+	}
+
+	msg.Instructions = make([]CompiledInstruction, len(instructions))
+	for i, ix := range instructions {
+		compiled := CompiledInstruction{ProgramIDIndex: positions[ix.ProgramID], Data: ix.Data}
+		if len(ix.Accounts) > 0 {
+			compiled.Accounts = make([]uint8, len(ix.Accounts))
+			for j, a := range ix.Accounts {
+				compiled.Accounts[j] = positions[a.PublicKey]
+			}
+		}
+		msg.Instructions[i] = compiled
+	}
+
+	return msg, nil
+}
+
+// NewTransaction returns an unsigned transaction for the message, with an empty signature for each signer.
+func NewTransaction(msg *Message) *Transaction {
+	return &Transaction{
+		Signatures: make([]Signature, msg.Header.NumRequiredSignatures),
+		Message:    *msg,
+	}
+}
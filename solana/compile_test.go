@@ -0,0 +1,146 @@
+package solana_test
+
+import (
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resolve returns the accounts referenced by the message, static keys first, then the keys loaded from the tables.
+func resolve(t *testing.T, msg *solana.Message, tables []solana.AddressLookupTable) []solana.PublicKey {
+	t.Helper()
+
+	keys := append([]solana.PublicKey(nil), msg.AccountKeys...)
+	var writable, readonly []solana.PublicKey
+	for _, l := range msg.AddressTableLookups {
+		var table *solana.AddressLookupTable
+		for i := range tables {
+			if tables[i].Key == l.AccountKey {
+				table = &tables[i]
+			}
+		}
+		require.NotNil(t, table)
+		for _, i := range l.WritableIndexes {
+			writable = append(writable, table.Addresses[i])
+		}
+		for _, i := range l.ReadonlyIndexes {
+			readonly = append(readonly, table.Addresses[i])
+		}
+	}
+
+	return append(append(keys, writable...), readonly...)
+}
+
+func TestNewMessageV0(t *testing.T) {
+	var (
+		signer   = solana.MustPublicKeyFromBase58("7Jbm4XGnLPZ6qe4JRQEZ5fiEikdrPThXGJzXDb4oX6yn")
+		program  = solana.JupiterAggregatorV6ProgramID
+		writable = solana.USDCMint
+		readonly = solana.WrappedSOLMint
+	)
+
+	instructions := []solana.Instruction{
+		{ProgramID: solana.ComputeBudgetProgramID, Data: []byte{2, 1, 0, 0, 0}},
+		{
+			ProgramID: program,
+			Accounts: []solana.AccountMeta{
+				solana.Meta(readonly, false, false),
+				solana.Meta(signer, true, false),
+				solana.Meta(writable, false, true),
+				solana.Meta(payer, true, true),
+				solana.Meta(readonly, false, false),
+			},
+			Data: []byte{1, 2, 3},
+		},
+		{ProgramID: solana.TokenProgramID, Accounts: []solana.AccountMeta{solana.Meta(writable, false, false)}},
+	}
+
+	// checkAccounts verifies that every instruction references the accounts it was compiled from with the right flags.
+	checkAccounts := func(t *testing.T, msg *solana.Message, tables []solana.AddressLookupTable) {
+		t.Helper()
+
+		keys := resolve(t, msg, tables)
+		require.Len(t, msg.Instructions, len(instructions))
+		for i, ix := range instructions {
+			compiled := msg.Instructions[i]
+			assert.Equal(t, ix.ProgramID, keys[compiled.ProgramIDIndex])
+			assert.Equal(t, ix.Data, compiled.Data)
+			require.Len(t, compiled.Accounts, len(ix.Accounts))
+			for j, a := range ix.Accounts {
+				idx := int(compiled.Accounts[j])
+				assert.Equal(t, a.PublicKey, keys[idx])
+				if a.IsSigner {
+					assert.True(t, msg.IsSigner(idx))
+				}
+				if a.IsWritable {
+					assert.True(t, msg.IsWritable(idx))
+				}
+			}
+		}
+	}
+
+	t.Run("without lookup tables", func(t *testing.T) {
+		msg, err := solana.NewMessageV0(payer, instructions, blockhash, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, solana.MessageVersionV0, msg.Version)
+		assert.Equal(t, blockhash, msg.RecentBlockhash)
+		assert.Equal(t, solana.MessageHeader{
+			NumRequiredSignatures:       2,
+			NumReadonlySignedAccounts:   1,
+			NumReadonlyUnsignedAccounts: 4,
+		}, msg.Header)
+		assert.Equal(t, []solana.PublicKey{
+			payer, signer, writable,
+			solana.ComputeBudgetProgramID, program, readonly, solana.TokenProgramID,
+		}, msg.AccountKeys)
+		assert.Empty(t, msg.AddressTableLookups)
+		checkAccounts(t, msg, nil)
+	})
+
+	t.Run("with lookup tables", func(t *testing.T) {
+		tables := []solana.AddressLookupTable{
+			{Key: table, Addresses: []solana.PublicKey{signer, program, solana.SystemProgramID, readonly}},
+			{Key: solana.USDTMint, Addresses: []solana.PublicKey{readonly, writable}},
+			{Key: solana.Token2022ProgramID, Addresses: []solana.PublicKey{solana.SystemProgramID}},
+		}
+
+		msg, err := solana.NewMessageV0(payer, instructions, blockhash, tables)
+		require.NoError(t, err)
+
+		// Signers, programs and accounts already loaded by a previous table stay in place.
+		assert.Equal(t, []solana.MessageAddressTableLookup{
+			{AccountKey: table, ReadonlyIndexes: []uint8{3}},
+			{AccountKey: solana.USDTMint, WritableIndexes: []uint8{1}},
+		}, msg.AddressTableLookups)
+		assert.Equal(t, []solana.PublicKey{
+			payer, signer,
+			solana.ComputeBudgetProgramID, program, solana.TokenProgramID,
+		}, msg.AccountKeys)
+		checkAccounts(t, msg, tables)
+
+		// Loaded accounts are ordered writable first.
+		keys := resolve(t, msg, tables)
+		assert.Equal(t, []solana.PublicKey{writable, readonly}, keys[len(msg.AccountKeys):])
+
+		b, err := solana.NewTransaction(msg).MarshalBinary()
+		require.NoError(t, err)
+		decoded, err := solana.TransactionFromBytes(b)
+		require.NoError(t, err)
+		assert.Equal(t, msg, &decoded.Message)
+		assert.Len(t, decoded.Signatures, 2)
+	})
+
+	t.Run("too many accounts", func(t *testing.T) {
+		ix := solana.Instruction{ProgramID: program}
+		for i := 0; i < 300; i++ {
+			var k solana.PublicKey
+			k[0], k[1] = byte(i), byte(i>>8)+1
+			ix.Accounts = append(ix.Accounts, solana.Meta(k, false, false))
+		}
+		_, err := solana.NewMessageV0(payer, []solana.Instruction{ix}, blockhash, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,106 @@
+package solana
+
+import "encoding/binary"
+
+// ComputeBudget program instruction discriminators.
+const (
+	computeBudgetRequestHeapFrame               = 1
+	computeBudgetSetComputeUnitLimit            = 2
+	computeBudgetSetComputeUnitPrice            = 3
+	computeBudgetSetLoadedAccountsDataSizeLimit = 4
+)
+
+// RequestHeapFrame requests a heap frame of the given size for the transaction.
+type RequestHeapFrame struct {
+	Bytes uint32
+}
+
+// SetComputeUnitLimit sets the compute unit limit of the transaction.
+type SetComputeUnitLimit struct {
+	Units uint32
+}
+
+// SetComputeUnitPrice sets the compute unit price of the transaction, in micro lamports.
+type SetComputeUnitPrice struct {
+	MicroLamports uint64
+}
+
+// SetLoadedAccountsDataSizeLimit sets the maximum size of the accounts loaded by the transaction.
+type SetLoadedAccountsDataSizeLimit struct {
+	Bytes uint32
+}
+
+// ProgramID implements DecodedInstruction.
+func (*RequestHeapFrame) ProgramID() PublicKey { return ComputeBudgetProgramID }
+
+// Name implements DecodedInstruction.
+func (*RequestHeapFrame) Name() string { return "RequestHeapFrame" }
+
+// ProgramID implements DecodedInstruction.
+func (*SetComputeUnitLimit) ProgramID() PublicKey { return ComputeBudgetProgramID }
+
+// Name implements DecodedInstruction.
+func (*SetComputeUnitLimit) Name() string { return "SetComputeUnitLimit" }
+
+// ProgramID implements DecodedInstruction.
+func (*SetComputeUnitPrice) ProgramID() PublicKey { return ComputeBudgetProgramID }
+
+// Name implements DecodedInstruction.
+func (*SetComputeUnitPrice) Name() string { return "SetComputeUnitPrice" }
+
+// ProgramID implements DecodedInstruction.
+func (*SetLoadedAccountsDataSizeLimit) ProgramID() PublicKey { return ComputeBudgetProgramID }
+
+// Name implements DecodedInstruction.
+func (*SetLoadedAccountsDataSizeLimit) Name() string { return "SetLoadedAccountsDataSizeLimit" }
+
+// Instruction returns the instruction.
+func (i *SetComputeUnitLimit) Instruction() Instruction {
+	data := make([]byte, 5)
+	data[0] = computeBudgetSetComputeUnitLimit
+	binary.LittleEndian.PutUint32(data[1:], i.Units)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+// Instruction returns the instruction.
+func (i *SetComputeUnitPrice) Instruction() Instruction {
+	data := make([]byte, 9)
+	data[0] = computeBudgetSetComputeUnitPrice
+	binary.LittleEndian.PutUint64(data[1:], i.MicroLamports)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+func decodeComputeBudget(ix Instruction) (DecodedInstruction, error) {
+	d := &decoder{b: ix.Data}
+	kind, err := d.byte()
+	if err != nil {
+		return nil, invalidData("compute budget", err)
+	}
+
+	var decoded DecodedInstruction
+	switch kind {
+	case computeBudgetRequestHeapFrame:
+		i := &RequestHeapFrame{}
+		i.Bytes, err = d.uint32()
+		decoded = i
+	case computeBudgetSetComputeUnitLimit:
+		i := &SetComputeUnitLimit{}
+		i.Units, err = d.uint32()
+		decoded = i
+	case computeBudgetSetComputeUnitPrice:
+		i := &SetComputeUnitPrice{}
+		i.MicroLamports, err = d.uint64()
+		decoded = i
+	case computeBudgetSetLoadedAccountsDataSizeLimit:
+		i := &SetLoadedAccountsDataSizeLimit{}
+		i.Bytes, err = d.uint32()
+		decoded = i
+	default:
+		return nil, unknownInstruction("compute budget", kind)
+	}
+	if err != nil {
+		return nil, invalidData(decoded.Name(), err)
+	}
+
+	return decoded, nil
+}
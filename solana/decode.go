@@ -0,0 +1,165 @@
+package solana
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Errors returned when decoding instructions.
+var (
+	ErrUnknownProgram     = errors.New("unknown program")
+	ErrUnknownInstruction = errors.New("unknown instruction")
+)
+
+// DecodedInstruction is a typed instruction returned by an InstructionDecoder,
+// such as *SetComputeUnitPrice or *TokenTransfer.
+type DecodedInstruction interface {
+	// ProgramID returns the program executing the instruction.
+	ProgramID() PublicKey
+	// Name returns the name of the instruction, e.g. "SetComputeUnitPrice".
+	Name() string
+}
+
+// InstructionDecoder decodes the instructions of a program.
+// It returns an error wrapping ErrUnknownInstruction for instructions it does not know.
+type InstructionDecoder func(ix Instruction) (DecodedInstruction, error)
+
+// DecoderRegistry maps programs to their instruction decoders. It is safe for concurrent use.
+type DecoderRegistry struct {
+	mu       sync.RWMutex
+	decoders map[PublicKey]InstructionDecoder
+}
+
+// NewDecoderRegistry returns an empty registry.
+func NewDecoderRegistry() *DecoderRegistry {
+	return &DecoderRegistry{decoders: make(map[PublicKey]InstructionDecoder)}
+}
+
+// DefaultDecoderRegistry knows the ComputeBudget, System, SPL Token, Token-2022, Associated Token
+// and Jupiter aggregator v6 programs. More programs can be registered.
+var DefaultDecoderRegistry = func() *DecoderRegistry {
+	r := NewDecoderRegistry()
+	r.Register(ComputeBudgetProgramID, decodeComputeBudget)
+	r.Register(SystemProgramID, decodeSystem)
+	r.Register(TokenProgramID, decodeToken)
+	r.Register(Token2022ProgramID, decodeToken)
+	r.Register(AssociatedTokenProgramID, decodeAssociatedToken)
+	r.Register(JupiterAggregatorV6ProgramID, decodeJupiter)
+	return r
+}()
+
+// Register sets the decoder of the instructions of the given program, replacing any previous one.
+func (r *DecoderRegistry) Register(programID PublicKey, decoder InstructionDecoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decoders[programID] = decoder
+}
+
+// Decode decodes the instruction with the decoder of its program.
+// It returns an error wrapping ErrUnknownProgram if no decoder is registered for the program.
+func (r *DecoderRegistry) Decode(ix Instruction) (DecodedInstruction, error) {
+	r.mu.RLock()
+	decoder, ok := r.decoders[ix.ProgramID]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownProgram, ix.ProgramID)
+	}
+
+	return decoder(ix)
+}
+
+// DecodeInstruction decodes the instruction with DefaultDecoderRegistry.
+func DecodeInstruction(ix Instruction) (DecodedInstruction, error) {
+	return DefaultDecoderRegistry.Decode(ix)
+}
+
+// accountsReader reads the accounts of an instruction in order.
+type accountsReader struct {
+	ix  Instruction
+	pos int
+	err error
+}
+
+// next returns the next account, recording an error if the instruction has too few accounts.
+func (r *accountsReader) next() PublicKey {
+	if r.pos >= len(r.ix.Accounts) {
+		if r.err == nil {
+			r.err = fmt.Errorf("instruction has %d accounts, want more", len(r.ix.Accounts))
+		}
+		return PublicKey{}
+	}
+	k := r.ix.Accounts[r.pos].PublicKey
+	r.pos++
+	return k
+}
+
+// unknownInstruction returns the error of an instruction a decoder does not know.
+func unknownInstruction(program string, discriminator interface{}) error {
+	return fmt.Errorf("%w: %s instruction %v", ErrUnknownInstruction, program, discriminator)
+}
+
+// invalidData wraps an error decoding the data of an instruction.
+func invalidData(name string, err error) error {
+	return fmt.Errorf("invalid %s data: %w", name, err)
+}
+
+// DecompileInstructions returns the instructions of the message with their accounts resolved.
+// The contents of the address lookup tables of a v0 message must be given.
+func (m *Message) DecompileInstructions(tables []AddressLookupTable) ([]Instruction, error) {
+	keys := append([]PublicKey(nil), m.AccountKeys...)
+	var readonly []PublicKey
+	for _, l := range m.AddressTableLookups {
+		var table *AddressLookupTable
+		for i := range tables {
+			if tables[i].Key == l.AccountKey {
+				table = &tables[i]
+				break
+			}
+		}
+		if table == nil {
+			return nil, fmt.Errorf("address lookup table %s is not resolved", l.AccountKey)
+		}
+
+		load := func(indexes []uint8) ([]PublicKey, error) {
+			loaded := make([]PublicKey, len(indexes))
+			for i, idx := range indexes {
+				if int(idx) >= len(table.Addresses) {
+					return nil, fmt.Errorf("index %d out of range of address lookup table %s", idx, table.Key)
+				}
+				loaded[i] = table.Addresses[idx]
+			}
+			return loaded, nil
+		}
+		writable, err := load(l.WritableIndexes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, writable...)
+		ro, err := load(l.ReadonlyIndexes)
+		if err != nil {
+			return nil, err
+		}
+		readonly = append(readonly, ro...)
+	}
+	keys = append(keys, readonly...)
+
+	instructions := make([]Instruction, len(m.Instructions))
+	for i, compiled := range m.Instructions {
+		if int(compiled.ProgramIDIndex) >= len(keys) {
+			return nil, fmt.Errorf("instruction %d: program index %d out of range", i, compiled.ProgramIDIndex)
+		}
+		ix := Instruction{ProgramID: keys[compiled.ProgramIDIndex], Data: compiled.Data}
+		for _, idx := range compiled.Accounts {
+			if int(idx) >= len(keys) {
+				return nil, fmt.Errorf("instruction %d: account index %d out of range", i, idx)
+			}
+			ix.Accounts = append(ix.Accounts, Meta(keys[idx], m.IsSigner(int(idx)), m.IsWritable(int(idx))))
+		}
+		instructions[i] = ix
+	}
+
+	return instructions, nil
+}
@@ -0,0 +1,168 @@
+package solana_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeInstruction(t *testing.T) {
+	var (
+		owner       = solana.MustPublicKeyFromBase58("7Jbm4XGnLPZ6qe4JRQEZ5fiEikdrPThXGJzXDb4oX6yn")
+		source      = solana.MustPublicKeyFromBase58("2immgwYNHBbyVQKVGCEkgWpi53bLwWNRMB5G2nbgYV17")
+		destination = solana.MustPublicKeyFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N")
+	)
+
+	tests := []struct {
+		name string
+		ix   interface {
+			solana.DecodedInstruction
+			Instruction() solana.Instruction
+		}
+	}{
+		{"SetComputeUnitLimit", &solana.SetComputeUnitLimit{Units: 1400000}},
+		{"SetComputeUnitPrice", &solana.SetComputeUnitPrice{MicroLamports: 1000}},
+		{"Transfer", &solana.SystemTransfer{From: payer, To: owner, Lamports: 5000}},
+		{"Transfer", &solana.TokenTransfer{Program: solana.TokenProgramID, Source: source, Destination: destination, Owner: owner, Amount: 42}},
+		{"TransferChecked", &solana.TokenTransferChecked{
+			Program: solana.Token2022ProgramID, Source: source, Mint: solana.USDCMint, Destination: destination, Owner: owner, Amount: 42, Decimals: 6,
+		}},
+		{"SyncNative", &solana.TokenSyncNative{Program: solana.TokenProgramID, Account: source}},
+		{"CloseAccount", &solana.TokenCloseAccount{Program: solana.TokenProgramID, Account: source, Destination: payer, Owner: payer}},
+		{"Create", &solana.CreateAssociatedTokenAccount{
+			Payer: payer, AssociatedAccount: source, Wallet: payer, Mint: solana.USDCMint, TokenProgram: solana.TokenProgramID,
+		}},
+		{"CreateIdempotent", &solana.CreateAssociatedTokenAccount{
+			Payer: payer, AssociatedAccount: source, Wallet: payer, Mint: solana.USDCMint, TokenProgram: solana.TokenProgramID, Idempotent: true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := solana.DecodeInstruction(tt.ix.Instruction())
+			require.NoError(t, err)
+			assert.Equal(t, tt.ix, decoded)
+			assert.Equal(t, tt.name, decoded.Name())
+			assert.Equal(t, tt.ix.Instruction().ProgramID, decoded.ProgramID())
+		})
+	}
+}
+
+func TestDecodeJupiterRoute(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString("5RfLl3rjrSoBAAAAJmQAAaCGAQAAAAAAXToAAAAAAAAyAAA=")
+	require.NoError(t, err)
+
+	ix := solana.Instruction{
+		ProgramID: solana.JupiterAggregatorV6ProgramID,
+		Accounts:  []solana.AccountMeta{solana.Meta(payer, true, true)},
+		Data:      data,
+	}
+	decoded, err := solana.DecodeInstruction(ix)
+	require.NoError(t, err)
+
+	route, ok := decoded.(*solana.JupiterRoute)
+	require.True(t, ok, "unexpected type %T", decoded)
+	assert.Equal(t, "Route", route.Name())
+	assert.False(t, route.SharedAccounts)
+	assert.False(t, route.ExactOut)
+	assert.Equal(t, 1, route.RoutePlanSteps)
+	assert.EqualValues(t, 100000, route.InAmount)
+	assert.EqualValues(t, 14941, route.QuotedOutAmount)
+	assert.EqualValues(t, 50, route.SlippageBps)
+	assert.EqualValues(t, 0, route.PlatformFeeBps)
+	assert.Equal(t, ix.Accounts, route.Accounts)
+
+	t.Run("truncated", func(t *testing.T) {
+		ix.Data = data[:20]
+		_, err := solana.DecodeInstruction(ix)
+		assert.ErrorContains(t, err, "invalid Route data")
+	})
+}
+
+func TestDecodeInstructionErrors(t *testing.T) {
+	t.Run("unknown program", func(t *testing.T) {
+		_, err := solana.DecodeInstruction(solana.Instruction{ProgramID: payer})
+		assert.ErrorIs(t, err, solana.ErrUnknownProgram)
+	})
+
+	t.Run("unknown instruction", func(t *testing.T) {
+		_, err := solana.DecodeInstruction(solana.Instruction{ProgramID: solana.ComputeBudgetProgramID, Data: []byte{42}})
+		assert.ErrorIs(t, err, solana.ErrUnknownInstruction)
+
+		_, err = solana.DecodeInstruction(solana.Instruction{ProgramID: solana.JupiterAggregatorV6ProgramID, Data: make([]byte, 8)})
+		assert.ErrorIs(t, err, solana.ErrUnknownInstruction)
+	})
+
+	t.Run("too few accounts", func(t *testing.T) {
+		ix := (&solana.TokenTransfer{Source: payer, Destination: payer, Owner: payer, Amount: 1}).Instruction()
+		ix.Accounts = ix.Accounts[:2]
+		_, err := solana.DecodeInstruction(ix)
+		assert.ErrorContains(t, err, "instruction has 2 accounts")
+	})
+
+	t.Run("short data", func(t *testing.T) {
+		_, err := solana.DecodeInstruction(solana.Instruction{ProgramID: solana.ComputeBudgetProgramID, Data: []byte{2, 1}})
+		assert.Error(t, err)
+	})
+}
+
+type memo struct{ text string }
+
+func (*memo) ProgramID() solana.PublicKey { return solana.SystemProgramID }
+func (*memo) Name() string                { return "Memo" }
+
+func TestDecoderRegistry(t *testing.T) {
+	r := solana.NewDecoderRegistry()
+
+	_, err := r.Decode(solana.Instruction{ProgramID: solana.ComputeBudgetProgramID})
+	require.ErrorIs(t, err, solana.ErrUnknownProgram)
+
+	program := solana.MustPublicKeyFromBase58("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+	r.Register(program, func(ix solana.Instruction) (solana.DecodedInstruction, error) {
+		return &memo{text: string(ix.Data)}, nil
+	})
+
+	decoded, err := r.Decode(solana.Instruction{ProgramID: program, Data: []byte("hello")})
+	require.NoError(t, err)
+	assert.Equal(t, &memo{text: "hello"}, decoded)
+}
+
+func TestDecompileInstructions(t *testing.T) {
+	lookupTable := solana.AddressLookupTable{
+		Key:       table,
+		Addresses: []solana.PublicKey{solana.USDCMint, solana.WrappedSOLMint},
+	}
+	instructions := []solana.Instruction{
+		(&solana.SetComputeUnitPrice{MicroLamports: 1000}).Instruction(),
+		{
+			ProgramID: solana.JupiterAggregatorV6ProgramID,
+			Accounts: []solana.AccountMeta{
+				solana.Meta(payer, true, true),
+				solana.Meta(solana.USDCMint, false, true),
+				solana.Meta(solana.WrappedSOLMint, false, false),
+			},
+			Data: []byte{1, 2, 3},
+		},
+	}
+
+	msg, err := solana.NewMessageV0(payer, instructions, blockhash, []solana.AddressLookupTable{lookupTable})
+	require.NoError(t, err)
+	require.Len(t, msg.AddressTableLookups, 1)
+
+	decompiled, err := msg.DecompileInstructions([]solana.AddressLookupTable{lookupTable})
+	require.NoError(t, err)
+	assert.Equal(t, instructions, decompiled)
+
+	_, err = msg.DecompileInstructions(nil)
+	assert.ErrorContains(t, err, "is not resolved")
+}
+
+func TestJupiterError(t *testing.T) {
+	assert.Equal(t, "SlippageToleranceExceeded", solana.JupiterErrorSlippageToleranceExceeded.String())
+	assert.Equal(t, "SourceAndDestinationMintCannotBeTheSame", solana.JupiterErrorSourceAndDestinationMintCannotBeTheSame.String())
+	assert.EqualError(t, solana.JupiterError(6001), "jupiter error 6001: SlippageToleranceExceeded")
+	assert.Equal(t, "JupiterError(7000)", solana.JupiterError(7000).String())
+}
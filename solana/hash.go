@@ -0,0 +1,59 @@
+package solana
+
+import "fmt"
+
+// HashLength is the length of a hash in bytes.
+const HashLength = 32
+
+// Hash is a SHA-256 hash, such as a recent blockhash. It is encoded in JSON and text as base58.
+type Hash [HashLength]byte
+
+// HashFromBase58 parses a base58 encoded hash.
+func HashFromBase58(s string) (Hash, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return Hash{}, fmt.Errorf("invalid hash %q: %w", s, err)
+	}
+	if len(b) != HashLength {
+		return Hash{}, fmt.Errorf("invalid hash %q: decoded length is %d, want %d", s, len(b), HashLength)
+	}
+
+	var h Hash
+	copy(h[:], b)
+
+	return h, nil
+}
+
+// MustHashFromBase58 is like HashFromBase58 but panics if the hash cannot be parsed.
+func MustHashFromBase58(s string) Hash {
+	h, err := HashFromBase58(s)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// String returns the base58 encoding of the hash.
+func (h Hash) String() string {
+	return EncodeBase58(h[:])
+}
+
+// IsZero reports whether the hash is all zeros.
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hash) UnmarshalText(text []byte) error {
+	parsed, err := HashFromBase58(string(text))
+	if err != nil {
+		return err
+	}
+	*h = parsed
+	return nil
+}
@@ -0,0 +1,26 @@
+package solana
+
+// AccountMeta is an account used by an instruction.
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+// Meta returns an AccountMeta for the key.
+func Meta(key PublicKey, isSigner, isWritable bool) AccountMeta {
+	return AccountMeta{PublicKey: key, IsSigner: isSigner, IsWritable: isWritable}
+}
+
+// Instruction is an instruction to be compiled into a message, see NewMessageV0.
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// AddressLookupTable is the content of an on-chain address lookup table.
+type AddressLookupTable struct {
+	Key       PublicKey   // address of the table
+	Addresses []PublicKey // addresses stored in the table
+}
@@ -0,0 +1,226 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// anchorDiscriminator returns the 8-byte discriminator of an Anchor instruction.
+func anchorDiscriminator(name string) [8]byte {
+	sum := sha256.Sum256([]byte("global:" + name))
+	var d [8]byte
+	copy(d[:], sum[:8])
+	return d
+}
+
+// jupiterRouteVariant describes the arguments of a Jupiter route instruction.
+type jupiterRouteVariant struct {
+	name           string // Anchor name
+	title          string // name returned by JupiterRoute.Name
+	discriminator  [8]byte
+	sharedAccounts bool
+	exactOut       bool
+	tokenLedger    bool
+}
+
+var jupiterRouteVariants = func() []jupiterRouteVariant {
+	variants := []jupiterRouteVariant{
+		{name: "route", title: "Route"},
+		{name: "shared_accounts_route", title: "SharedAccountsRoute", sharedAccounts: true},
+		{name: "exact_out_route", title: "ExactOutRoute", exactOut: true},
+		{name: "shared_accounts_exact_out_route", title: "SharedAccountsExactOutRoute", sharedAccounts: true, exactOut: true},
+		{name: "route_with_token_ledger", title: "RouteWithTokenLedger", tokenLedger: true},
+		{name: "shared_accounts_route_with_token_ledger", title: "SharedAccountsRouteWithTokenLedger", sharedAccounts: true, tokenLedger: true},
+	}
+	for i := range variants {
+		variants[i].discriminator = anchorDiscriminator(variants[i].name)
+	}
+	return variants
+}()
+
+var jupiterSetTokenLedgerDiscriminator = anchorDiscriminator("set_token_ledger")
+
+// JupiterRoute is a swap through a Jupiter aggregator v6 route instruction.
+// The route plan itself is not decoded, only its number of steps.
+type JupiterRoute struct {
+	Instruction    string // name of the instruction, e.g. "SharedAccountsRoute"
+	SharedAccounts bool   // the swap uses the program shared token accounts
+	ExactOut       bool   // the output amount is fixed, the input amount is bounded by QuotedInAmount
+	TokenLedger    bool   // the input amount is the increase of the input token account since SetTokenLedger
+
+	ID              uint8 // shared accounts program authority id
+	RoutePlanSteps  int
+	InAmount        uint64 // ExactIn only, not set with a token ledger
+	QuotedOutAmount uint64 // ExactIn only, minimum output before slippage
+	OutAmount       uint64 // ExactOut only
+	QuotedInAmount  uint64 // ExactOut only, maximum input before slippage
+	SlippageBps     uint16
+	PlatformFeeBps  uint8
+
+	Accounts []AccountMeta
+}
+
+// JupiterSetTokenLedger records the amount of a token account, see JupiterRoute.TokenLedger.
+type JupiterSetTokenLedger struct {
+	TokenLedger  PublicKey
+	TokenAccount PublicKey
+}
+
+// ProgramID implements DecodedInstruction.
+func (*JupiterRoute) ProgramID() PublicKey { return JupiterAggregatorV6ProgramID }
+
+// Name implements DecodedInstruction.
+func (i *JupiterRoute) Name() string { return i.Instruction }
+
+// ProgramID implements DecodedInstruction.
+func (*JupiterSetTokenLedger) ProgramID() PublicKey { return JupiterAggregatorV6ProgramID }
+
+// Name implements DecodedInstruction.
+func (*JupiterSetTokenLedger) Name() string { return "SetTokenLedger" }
+
+func decodeJupiter(ix Instruction) (DecodedInstruction, error) {
+	if len(ix.Data) < 8 {
+		return nil, invalidData("jupiter", errShortBuffer)
+	}
+
+	if bytes.Equal(ix.Data[:8], jupiterSetTokenLedgerDiscriminator[:]) {
+		a := &accountsReader{ix: ix}
+		i := &JupiterSetTokenLedger{TokenLedger: a.next(), TokenAccount: a.next()}
+		if a.err != nil {
+			return nil, invalidData(i.Name(), a.err)
+		}
+		return i, nil
+	}
+
+	for _, v := range jupiterRouteVariants {
+		if bytes.Equal(ix.Data[:8], v.discriminator[:]) {
+			i, err := decodeJupiterRoute(v, ix)
+			if err != nil {
+				return nil, invalidData(v.title, err)
+			}
+			return i, nil
+		}
+	}
+
+	return nil, unknownInstruction("jupiter", fmt.Sprintf("%x", ix.Data[:8]))
+}
+
+// decodeJupiterRoute decodes the arguments of a route instruction. The route plan steps
+// have a variable size, so the fixed size arguments following the plan are read from the end.
+func decodeJupiterRoute(v jupiterRouteVariant, ix Instruction) (*JupiterRoute, error) {
+	i := &JupiterRoute{
+		Instruction:    v.title,
+		SharedAccounts: v.sharedAccounts,
+		ExactOut:       v.exactOut,
+		TokenLedger:    v.tokenLedger,
+		Accounts:       ix.Accounts,
+	}
+
+	d := &decoder{b: ix.Data, pos: 8}
+	var err error
+	if v.sharedAccounts {
+		if i.ID, err = d.byte(); err != nil {
+			return nil, err
+		}
+	}
+	steps, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	i.RoutePlanSteps = int(steps)
+
+	tail := 8 + 8 + 2 + 1
+	if v.tokenLedger {
+		tail = 8 + 2 + 1
+	}
+	if d.remaining() < tail {
+		return nil, errShortBuffer
+	}
+	d.pos = len(d.b) - tail
+
+	switch {
+	case v.exactOut:
+		if i.OutAmount, err = d.uint64(); err == nil {
+			i.QuotedInAmount, err = d.uint64()
+		}
+	case v.tokenLedger:
+		i.QuotedOutAmount, err = d.uint64()
+	default:
+		if i.InAmount, err = d.uint64(); err == nil {
+			i.QuotedOutAmount, err = d.uint64()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if i.SlippageBps, err = d.uint16(); err != nil {
+		return nil, err
+	}
+	if i.PlatformFeeBps, err = d.byte(); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// JupiterError is a custom program error of the Jupiter aggregator v6 program.
+type JupiterError uint32
+
+// Jupiter aggregator v6 program errors.
+const (
+	JupiterErrorEmptyRoute JupiterError = 6000 + iota
+	JupiterErrorSlippageToleranceExceeded
+	JupiterErrorInvalidCalculation
+	JupiterErrorMissingPlatformFeeAccount
+	JupiterErrorInvalidSlippage
+	JupiterErrorNotEnoughPercent
+	JupiterErrorInvalidInputIndex
+	JupiterErrorInvalidOutputIndex
+	JupiterErrorNotEnoughAccountKeys
+	JupiterErrorNonZeroMinimumOutAmountNotSupported
+	JupiterErrorInvalidRoutePlan
+	JupiterErrorInvalidReferralAuthority
+	JupiterErrorLedgerTokenAccountDoesNotMatch
+	JupiterErrorInvalidTokenLedger
+	JupiterErrorIncorrectTokenProgramID
+	JupiterErrorTokenProgramNotProvided
+	JupiterErrorSwapNotSupported
+	JupiterErrorExactOutAmountNotMatched
+	JupiterErrorSourceAndDestinationMintCannotBeTheSame
+)
+
+var jupiterErrorNames = [...]string{
+	"EmptyRoute",
+	"SlippageToleranceExceeded",
+	"InvalidCalculation",
+	"MissingPlatformFeeAccount",
+	"InvalidSlippage",
+	"NotEnoughPercent",
+	"InvalidInputIndex",
+	"InvalidOutputIndex",
+	"NotEnoughAccountKeys",
+	"NonZeroMinimumOutAmountNotSupported",
+	"InvalidRoutePlan",
+	"InvalidReferralAuthority",
+	"LedgerTokenAccountDoesNotMatch",
+	"InvalidTokenLedger",
+	"IncorrectTokenProgramID",
+	"TokenProgramNotProvided",
+	"SwapNotSupported",
+	"ExactOutAmountNotMatched",
+	"SourceAndDestinationMintCannotBeTheSame",
+}
+
+// String returns the name of the error, e.g. "SlippageToleranceExceeded".
+func (e JupiterError) String() string {
+	if e >= JupiterErrorEmptyRoute && int(e-JupiterErrorEmptyRoute) < len(jupiterErrorNames) {
+		return jupiterErrorNames[e-JupiterErrorEmptyRoute]
+	}
+	return fmt.Sprintf("JupiterError(%d)", uint32(e))
+}
+
+// Error implements the error interface.
+func (e JupiterError) Error() string {
+	return fmt.Sprintf("jupiter error %d: %s", uint32(e), e.String())
+}
@@ -0,0 +1,109 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// MaxSeedLength is the maximum length of a program derived address seed.
+const MaxSeedLength = 32
+
+// maxSeeds is the maximum number of seeds of a program derived address.
+const maxSeeds = 16
+
+// ErrOnCurve is returned by CreateProgramAddress when the seeds derive a valid ed25519 public key,
+// which cannot be used as a program derived address.
+var ErrOnCurve = errors.New("program address is on the ed25519 curve")
+
+// CreateProgramAddress returns the program derived address of the seeds, which must include the bump seed.
+func CreateProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, error) {
+	if len(seeds) > maxSeeds {
+		return PublicKey{}, fmt.Errorf("too many seeds: %d, max is %d", len(seeds), maxSeeds)
+	}
+
+	h := sha256.New()
+	for _, seed := range seeds {
+		if len(seed) > MaxSeedLength {
+			return PublicKey{}, fmt.Errorf("seed is %d bytes, max is %d", len(seed), MaxSeedLength)
+		}
+		h.Write(seed)
+	}
+	h.Write(programID[:])
+	h.Write([]byte("ProgramDerivedAddress"))
+
+	var k PublicKey
+	copy(k[:], h.Sum(nil))
+	if k.IsOnCurve() {
+		return PublicKey{}, ErrOnCurve
+	}
+
+	return k, nil
+}
+
+// FindProgramAddress returns the first program derived address of the seeds found with a bump seed
+// counting down from 255, along with the bump seed.
+func FindProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, uint8, error) {
+	withBump := append(append([][]byte(nil), seeds...), nil)
+	for bump := 255; bump >= 0; bump-- {
+		withBump[len(seeds)] = []byte{uint8(bump)}
+		k, err := CreateProgramAddress(withBump, programID)
+		if errors.Is(err, ErrOnCurve) {
+			continue
+		}
+		if err != nil {
+			return PublicKey{}, 0, err
+		}
+		return k, uint8(bump), nil
+	}
+
+	return PublicKey{}, 0, fmt.Errorf("no valid bump seed found")
+}
+
+// FindAssociatedTokenAddress returns the associated token account of the wallet for the mint.
+// The SPL Token program is used unless program is set, Token-2022 mints need Token2022ProgramID.
+func FindAssociatedTokenAddress(wallet, mint, program PublicKey) (PublicKey, error) {
+	program = tokenProgram(program)
+	k, _, err := FindProgramAddress([][]byte{wallet[:], program[:], mint[:]}, AssociatedTokenProgramID)
+	return k, err
+}
+
+var (
+	// curveP is the prime of the ed25519 base field, 2^255 - 19.
+	curveP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// curveD is the d constant of the ed25519 curve, -121665/121666.
+	curveD = func() *big.Int {
+		d := new(big.Int).ModInverse(big.NewInt(121666), curveP)
+		d.Mul(d, big.NewInt(-121665))
+		return d.Mod(d, curveP)
+	}()
+)
+
+// IsOnCurve reports whether the key is the compressed encoding of a point of the ed25519 curve,
+// that is whether x^2 = (y^2 - 1) / (d*y^2 + 1) has a solution for its y coordinate.
+// Keys of keypairs are on the curve, program derived addresses are not.
+func (k PublicKey) IsOnCurve() bool {
+	// The y coordinate is encoded in little-endian, the top bit being the sign of x.
+	le := k
+	le[31] &= 0x7f
+	be := make([]byte, len(le))
+	for i, b := range le {
+		be[len(be)-1-i] = b
+	}
+	y := new(big.Int).SetBytes(be)
+	y.Mod(y, curveP)
+
+	y2 := new(big.Int).Mul(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	u.Mod(u, curveP)
+	v := new(big.Int).Mul(curveD, y2)
+	v.Add(v, big.NewInt(1))
+	v.Mod(v, curveP)
+
+	x2 := v.ModInverse(v, curveP)
+	x2.Mul(x2, u)
+	x2.Mod(x2, curveP)
+
+	return x2.Sign() == 0 || big.Jacobi(x2, curveP) == 1
+}
@@ -0,0 +1,74 @@
+package solana_test
+
+import (
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProgramAddress(t *testing.T) {
+	program := solana.MustPublicKeyFromBase58("BPFLoader1111111111111111111111111111111111")
+	seedKey := solana.MustPublicKeyFromBase58("SeedPubey1111111111111111111111111111111111")
+
+	// Addresses from the test suite of @solana/web3.js.
+	tests := []struct {
+		name  string
+		seeds [][]byte
+		want  string
+	}{
+		{name: "empty seed and bump", seeds: [][]byte{{}, {1}}, want: "3gF2KMe9KiC6FNVBmfg9i267aMPvK37FewCip4eGBFcT"},
+		{name: "utf-8 seed", seeds: [][]byte{[]byte("☉")}, want: "7ytmC1nT1xY4RfxCV2ZgyA7UakC93do5ZdyhdF3EtPj7"},
+		{name: "several seeds", seeds: [][]byte{[]byte("Talking"), []byte("Squirrels")}, want: "HwRVBufQ4haG5XSgpspwKtNd3PC9GM9m1196uJW36vds"},
+		{name: "public key seed", seeds: [][]byte{seedKey[:]}, want: "GUs5qLUfsEHkcMB9T38vjr18ypEhRuNWiePW2LoK4E3K"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := solana.CreateProgramAddress(tt.seeds, program)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, k.String())
+			assert.False(t, k.IsOnCurve())
+		})
+	}
+
+	_, err := solana.CreateProgramAddress([][]byte{make([]byte, solana.MaxSeedLength+1)}, program)
+	assert.ErrorContains(t, err, "seed is 33 bytes")
+
+	_, err = solana.CreateProgramAddress(make([][]byte, 17), program)
+	assert.ErrorContains(t, err, "too many seeds")
+}
+
+func TestFindProgramAddress(t *testing.T) {
+	wallet := solana.MustPublicKeyFromBase58("8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W")
+	seeds := [][]byte{wallet[:], solana.TokenProgramID[:], solana.USDCMint[:]}
+
+	k, bump, err := solana.FindProgramAddress(seeds, solana.AssociatedTokenProgramID)
+	require.NoError(t, err)
+	assert.False(t, k.IsOnCurve())
+
+	// Higher bump seeds derive addresses on the curve.
+	for b := 255; b > int(bump); b-- {
+		_, err := solana.CreateProgramAddress(append(seeds, []byte{uint8(b)}), solana.AssociatedTokenProgramID)
+		assert.ErrorIs(t, err, solana.ErrOnCurve)
+	}
+	created, err := solana.CreateProgramAddress(append(seeds, []byte{bump}), solana.AssociatedTokenProgramID)
+	require.NoError(t, err)
+	assert.Equal(t, k, created)
+
+	ata, err := solana.FindAssociatedTokenAddress(wallet, solana.USDCMint, solana.PublicKey{})
+	require.NoError(t, err)
+	assert.Equal(t, k, ata)
+
+	ata2022, err := solana.FindAssociatedTokenAddress(wallet, solana.USDCMint, solana.Token2022ProgramID)
+	require.NoError(t, err)
+	assert.NotEqual(t, ata, ata2022)
+}
+
+func TestPublicKeyIsOnCurve(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		kp, err := solana.NewKeypair()
+		require.NoError(t, err)
+		assert.True(t, kp.PublicKey().IsOnCurve())
+	}
+}
@@ -0,0 +1,110 @@
+// Package solana provides the Solana primitives needed to work with Jupiter swaps:
+// public keys, transactions and instructions.
+package solana
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// PublicKeyLength is the length of a public key in bytes.
+const PublicKeyLength = 32
+
+// PublicKey is a Solana public key, also used for account, mint and program addresses.
+// It is encoded in JSON, text and URL query strings as base58.
+type PublicKey [PublicKeyLength]byte
+
+// Well-known addresses.
+var (
+	SystemProgramID              = MustPublicKeyFromBase58("11111111111111111111111111111111")
+	ComputeBudgetProgramID       = MustPublicKeyFromBase58("ComputeBudget111111111111111111111111111111")
+	TokenProgramID               = MustPublicKeyFromBase58("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+	Token2022ProgramID           = MustPublicKeyFromBase58("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+	AssociatedTokenProgramID     = MustPublicKeyFromBase58("ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL")
+	AddressLookupTableProgramID  = MustPublicKeyFromBase58("AddressLookupTab1e1111111111111111111111111")
+	JupiterAggregatorV6ProgramID = MustPublicKeyFromBase58("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+
+	WrappedSOLMint = MustPublicKeyFromBase58("So11111111111111111111111111111111111111112")
+	USDCMint       = MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	USDTMint       = MustPublicKeyFromBase58("Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB")
+)
+
+// PublicKeyFromBase58 parses a base58 encoded public key.
+func PublicKeyFromBase58(s string) (PublicKey, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid public key %q: %w", s, err)
+	}
+	if len(b) != PublicKeyLength {
+		return PublicKey{}, fmt.Errorf("invalid public key %q: decoded length is %d, want %d", s, len(b), PublicKeyLength)
+	}
+
+	var k PublicKey
+	copy(k[:], b)
+
+	return k, nil
+}
+
+// MustPublicKeyFromBase58 is like PublicKeyFromBase58 but panics if the key cannot be parsed.
+func MustPublicKeyFromBase58(s string) PublicKey {
+	k, err := PublicKeyFromBase58(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// PublicKeyFromBytes returns the public key of the given 32 bytes.
+func PublicKeyFromBytes(b []byte) (PublicKey, error) {
+	if len(b) != PublicKeyLength {
+		return PublicKey{}, fmt.Errorf("invalid public key length %d, want %d", len(b), PublicKeyLength)
+	}
+
+	var k PublicKey
+	copy(k[:], b)
+
+	return k, nil
+}
+
+// String returns the base58 encoding of the key.
+func (k PublicKey) String() string {
+	return EncodeBase58(k[:])
+}
+
+// Bytes returns the key as a byte slice.
+func (k PublicKey) Bytes() []byte {
+	return append([]byte(nil), k[:]...)
+}
+
+// IsZero reports whether the key is all zeros, which is the case for an unset key.
+// The System Program address, SystemProgramID, is all zeros too, so IsZero cannot tell it from an
+// unset key: fields where the System Program is a valid value must use a *PublicKey to be optional.
+func (k PublicKey) IsZero() bool {
+	return k == PublicKey{}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k PublicKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. An empty text decodes as the zero key,
+// the way APIs leave optional keys empty; MarshalText encodes it as SystemProgramID.
+func (k *PublicKey) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*k = PublicKey{}
+		return nil
+	}
+	parsed, err := PublicKeyFromBase58(string(text))
+	if err != nil {
+		return err
+	}
+	*k = parsed
+	return nil
+}
+
+// EncodeValues implements query.Encoder of github.com/google/go-querystring, so keys are encoded as base58.
+func (k PublicKey) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, k.String())
+	return nil
+}
@@ -0,0 +1,99 @@
+package solana_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase58(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoded []byte
+		encoded string
+	}{
+		{name: "empty", decoded: []byte{}, encoded: ""},
+		{name: "zero", decoded: []byte{0}, encoded: "1"},
+		{name: "leading zeros", decoded: []byte{0, 0, 1}, encoded: "112"},
+		{name: "text", decoded: []byte("hello world"), encoded: "StV1DL6CwTryKyV"},
+		{name: "max byte", decoded: []byte{0xff}, encoded: "5Q"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.encoded, solana.EncodeBase58(tt.decoded))
+
+			decoded, err := solana.DecodeBase58(tt.encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.decoded, decoded)
+		})
+	}
+
+	_, err := solana.DecodeBase58("0OIl")
+	assert.Error(t, err)
+}
+
+func TestPublicKey(t *testing.T) {
+	const s = "8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W"
+
+	t.Run("parse", func(t *testing.T) {
+		k, err := solana.PublicKeyFromBase58(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, k.String())
+		assert.False(t, k.IsZero())
+
+		fromBytes, err := solana.PublicKeyFromBytes(k.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, k, fromBytes)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, in := range []string{"", "not a key", "8HwPMNxt", s + "1"} {
+			_, err := solana.PublicKeyFromBase58(in)
+			assert.Error(t, err, in)
+		}
+		_, err := solana.PublicKeyFromBytes(make([]byte, 31))
+		assert.Error(t, err)
+		assert.Panics(t, func() { solana.MustPublicKeyFromBase58("invalid") })
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		var k solana.PublicKey
+		assert.True(t, k.IsZero())
+		assert.Equal(t, "11111111111111111111111111111111", k.String())
+		assert.Equal(t, solana.SystemProgramID, k)
+		assert.True(t, solana.SystemProgramID.IsZero(), "the System Program cannot be told from an unset key")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		type doc struct {
+			Key      solana.PublicKey   `json:"key"`
+			Optional *solana.PublicKey  `json:"optional,omitempty"`
+			Keys     []solana.PublicKey `json:"keys"`
+		}
+
+		in := doc{Key: solana.USDCMint, Keys: []solana.PublicKey{solana.WrappedSOLMint}}
+		buf, err := json.Marshal(in)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"key":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","keys":["So11111111111111111111111111111111111111112"]}`, string(buf))
+
+		var out doc
+		require.NoError(t, json.Unmarshal(buf, &out))
+		assert.Equal(t, in, out)
+
+		assert.Error(t, json.Unmarshal([]byte(`{"key":"invalid"}`), &out))
+
+		require.NoError(t, json.Unmarshal([]byte(`{"key":"","keys":[""]}`), &out))
+		assert.True(t, out.Key.IsZero())
+		assert.Equal(t, []solana.PublicKey{{}}, out.Keys)
+	})
+
+	t.Run("url values", func(t *testing.T) {
+		v := url.Values{}
+		require.NoError(t, solana.USDCMint.EncodeValues("mint", &v))
+		assert.Equal(t, "mint=EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", v.Encode())
+	})
+}
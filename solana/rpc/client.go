@@ -0,0 +1,281 @@
+// Package rpc is a minimal Solana JSON-RPC client covering what is needed to send Jupiter swaps:
+// recent blockhashes, address lookup tables, simulation, sending and confirming transactions.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/qiruos/jupiter/solana"
+)
+
+// DefaultAPIURL is the public mainnet-beta RPC endpoint. It is heavily rate limited,
+// use a dedicated RPC provider in production.
+const DefaultAPIURL = "https://api.mainnet-beta.solana.com"
+
+// maxDrainSize limits how much of an unread response body is discarded to reuse the connection.
+const maxDrainSize = 256 << 10
+
+// ErrAccountNotFound is returned when the requested account does not exist.
+var ErrAccountNotFound = errors.New("account not found")
+
+type (
+	// Client is a Solana JSON-RPC client.
+	Client struct {
+		client *http.Client
+		apiURL string
+
+		id uint64
+	}
+
+	// ClientOption is a function that can be used to configure an RPC client.
+	ClientOption func(*Client)
+
+	request struct {
+		JSONRPC string        `json:"jsonrpc"`
+		ID      uint64        `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params,omitempty"`
+	}
+
+	response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+)
+
+// NewClient returns a new RPC client.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		apiURL: DefaultAPIURL,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// call makes a JSON-RPC request and decodes its result into result.
+// JSON-RPC errors are returned as *Error.
+func (c *Client) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(request{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&c.id, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s params: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make %s request: %w", method, err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code: %d from %s: %s", resp.StatusCode, method, bytes.TrimSpace(msg))
+	}
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if r.Error != nil {
+		return fmt.Errorf("%s failed: %w", method, r.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+
+	return nil
+}
+
+// commitmentParams returns the params of a method only taking a commitment configuration.
+func commitmentParams(commitment Commitment) []interface{} {
+	if commitment == "" {
+		return nil
+	}
+	return []interface{}{commitmentConfig{Commitment: commitment}}
+}
+
+// GetLatestBlockhash returns the latest blockhash and the last block height at which it is valid.
+// An empty commitment uses the node default, finalized.
+func (c *Client) GetLatestBlockhash(ctx context.Context, commitment Commitment) (*LatestBlockhash, error) {
+	var result struct {
+		Context Context         `json:"context"`
+		Value   LatestBlockhash `json:"value"`
+	}
+	if err := c.call(ctx, "getLatestBlockhash", commitmentParams(commitment), &result); err != nil {
+		return nil, err
+	}
+
+	result.Value.Slot = result.Context.Slot
+	return &result.Value, nil
+}
+
+// GetBlockHeight returns the current block height of the node.
+func (c *Client) GetBlockHeight(ctx context.Context, commitment Commitment) (uint64, error) {
+	var height uint64
+	if err := c.call(ctx, "getBlockHeight", commitmentParams(commitment), &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// GetAccountInfo returns the account at the given address.
+// It returns an error wrapping ErrAccountNotFound if the account does not exist.
+func (c *Client) GetAccountInfo(ctx context.Context, address solana.PublicKey, commitment Commitment) (*Account, error) {
+	var result struct {
+		Value *Account `json:"value"`
+	}
+	params := []interface{}{address, accountConfig{Encoding: "base64", Commitment: commitment}}
+	if err := c.call(ctx, "getAccountInfo", params, &result); err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, address)
+	}
+
+	return result.Value, nil
+}
+
+// GetAddressLookupTable returns the addresses stored in the address lookup table account at the given address.
+func (c *Client) GetAddressLookupTable(ctx context.Context, address solana.PublicKey) (solana.AddressLookupTable, error) {
+	account, err := c.GetAccountInfo(ctx, address, "")
+	if err != nil {
+		return solana.AddressLookupTable{}, err
+	}
+	if account.Owner != solana.AddressLookupTableProgramID {
+		return solana.AddressLookupTable{}, fmt.Errorf("account %s is not an address lookup table, owner is %s", address, account.Owner)
+	}
+
+	table, err := ParseAddressLookupTable(address, account.Data)
+	if err != nil {
+		return solana.AddressLookupTable{}, fmt.Errorf("invalid address lookup table %s: %w", address, err)
+	}
+
+	return table, nil
+}
+
+// GetAddressLookupTables returns the address lookup tables at the given addresses,
+// e.g. v6.SwapInstructionsResp.AddressLookupTableAddresses.
+func (c *Client) GetAddressLookupTables(ctx context.Context, addresses ...solana.PublicKey) ([]solana.AddressLookupTable, error) {
+	tables := make([]solana.AddressLookupTable, len(addresses))
+	for i, address := range addresses {
+		table, err := c.GetAddressLookupTable(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		tables[i] = table
+	}
+	return tables, nil
+}
+
+// SimulateTransaction simulates the transaction. A failed transaction is not an error of the call:
+// it is reported in SimulationResult.Err along with the program logs.
+func (c *Client) SimulateTransaction(ctx context.Context, tx *solana.Transaction, opts *SimulateTransactionOptions) (*SimulationResult, error) {
+	encoded, err := tx.Base64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	if opts == nil {
+		opts = &SimulateTransactionOptions{}
+	}
+
+	config := simulateConfig{
+		Encoding:               "base64",
+		SigVerify:              opts.SigVerify,
+		ReplaceRecentBlockhash: opts.ReplaceRecentBlockhash,
+		Commitment:             opts.Commitment,
+	}
+	if len(opts.Accounts) > 0 {
+		config.Accounts = &simulateAccountsConfig{Encoding: "base64", Addresses: opts.Accounts}
+	}
+
+	var result struct {
+		Context Context          `json:"context"`
+		Value   SimulationResult `json:"value"`
+	}
+	if err := c.call(ctx, "simulateTransaction", []interface{}{encoded, config}, &result); err != nil {
+		return nil, err
+	}
+
+	result.Value.Slot = result.Context.Slot
+	return &result.Value, nil
+}
+
+// SendTransaction submits the signed transaction to the cluster and returns its signature.
+// The transaction is not confirmed yet when the call returns, see GetSignatureStatuses.
+func (c *Client) SendTransaction(ctx context.Context, tx *solana.Transaction, opts *SendTransactionOptions) (solana.Signature, error) {
+	encoded, err := tx.Base64()
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	if opts == nil {
+		opts = &SendTransactionOptions{}
+	}
+
+	config := sendConfig{
+		Encoding:            "base64",
+		SkipPreflight:       opts.SkipPreflight,
+		PreflightCommitment: opts.PreflightCommitment,
+		MaxRetries:          opts.MaxRetries,
+	}
+
+	var signature solana.Signature
+	if err := c.call(ctx, "sendTransaction", []interface{}{encoded, config}, &signature); err != nil {
+		return solana.Signature{}, err
+	}
+
+	return signature, nil
+}
+
+// GetSignatureStatuses returns the statuses of the given signatures, in the same order.
+// The status of a signature unknown to the node is nil. Only recent signatures are searched
+// unless searchHistory is set.
+func (c *Client) GetSignatureStatuses(ctx context.Context, searchHistory bool, signatures ...solana.Signature) ([]*SignatureStatus, error) {
+	var result struct {
+		Context Context            `json:"context"`
+		Value   []*SignatureStatus `json:"value"`
+	}
+	params := []interface{}{signatures}
+	if searchHistory {
+		params = append(params, signatureStatusesConfig{SearchTransactionHistory: true})
+	}
+	if err := c.call(ctx, "getSignatureStatuses", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Value) != len(signatures) {
+		return nil, fmt.Errorf("got %d signature statuses, want %d", len(result.Value), len(signatures))
+	}
+
+	return result.Value, nil
+}
@@ -0,0 +1,20 @@
+package rpc
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WithHTTPClient returns a ClientOption that configures the HTTP client used by the RPC client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithAPIURL returns a ClientOption that configures the URL of the RPC node used by the RPC client.
+func WithAPIURL(apiURL string) ClientOption {
+	return func(c *Client) {
+		c.apiURL = strings.TrimRight(apiURL, "/")
+	}
+}
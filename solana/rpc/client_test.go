@@ -0,0 +1,275 @@
+package rpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/qiruos/jupiter/solana/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var table = solana.MustPublicKeyFromBase58("2immgwYNHBbyVQKVGCEkgWpi53bLwWNRMB5G2nbgYV17")
+
+// signedTransaction returns a compute budget transaction signed by a new keypair.
+func signedTransaction(t *testing.T) *solana.Transaction {
+	t.Helper()
+
+	signer, err := solana.NewKeypair()
+	require.NoError(t, err)
+	msg, err := solana.NewMessageV0(signer.PublicKey(), []solana.Instruction{
+		(&solana.SetComputeUnitLimit{Units: 200000}).Instruction(),
+	}, rpctest.DefaultBlockhash, nil)
+	require.NoError(t, err)
+
+	tx := solana.NewTransaction(msg)
+	require.NoError(t, tx.Sign(context.Background(), signer))
+	return tx
+}
+
+func TestGetLatestBlockhash(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	blockhash, err := srv.Client().GetLatestBlockhash(context.Background(), rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, rpctest.DefaultBlockhash, blockhash.Blockhash)
+	assert.EqualValues(t, rpctest.DefaultBlockHeight+rpctest.BlockhashValidity, blockhash.LastValidBlockHeight)
+	assert.EqualValues(t, rpctest.DefaultSlot, blockhash.Slot)
+
+	reqs := srv.Requests(rpctest.MethodGetLatestBlockhash)
+	require.Len(t, reqs, 1)
+	assert.JSONEq(t, `[{"commitment":"confirmed"}]`, string(reqs[0].Params))
+}
+
+func TestGetBlockHeight(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	height, err := c.GetBlockHeight(context.Background(), "")
+	require.NoError(t, err)
+	assert.EqualValues(t, rpctest.DefaultBlockHeight, height)
+
+	srv.SetBlockHeight(1234)
+	height, err = c.GetBlockHeight(context.Background(), rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1234, height)
+
+	reqs := srv.Requests(rpctest.MethodGetBlockHeight)
+	require.Len(t, reqs, 2)
+	assert.Empty(t, reqs[0].Params)
+	assert.JSONEq(t, `[{"commitment":"finalized"}]`, string(reqs[1].Params))
+}
+
+func TestGetAddressLookupTable(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	want := solana.AddressLookupTable{
+		Key:       table,
+		Addresses: []solana.PublicKey{solana.TokenProgramID, solana.USDCMint, solana.WrappedSOLMint},
+	}
+	srv.SetAddressLookupTable(want)
+
+	t.Run("found", func(t *testing.T) {
+		tables, err := c.GetAddressLookupTables(context.Background(), table)
+		require.NoError(t, err)
+		assert.Equal(t, []solana.AddressLookupTable{want}, tables)
+
+		req := srv.Requests(rpctest.MethodGetAccountInfo)[0]
+		var params []json.RawMessage
+		require.NoError(t, req.DecodeParams(&params))
+		require.Len(t, params, 2)
+		assert.JSONEq(t, `"`+table.String()+`"`, string(params[0]))
+		assert.JSONEq(t, `{"encoding":"base64"}`, string(params[1]))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := c.GetAddressLookupTable(context.Background(), solana.USDCMint)
+		assert.ErrorIs(t, err, rpc.ErrAccountNotFound)
+	})
+
+	t.Run("not a lookup table", func(t *testing.T) {
+		srv.SetAccount(solana.USDCMint, &rpc.Account{Owner: solana.TokenProgramID, Data: make([]byte, 82)})
+		_, err := c.GetAddressLookupTable(context.Background(), solana.USDCMint)
+		assert.ErrorContains(t, err, "is not an address lookup table")
+	})
+}
+
+func TestParseAddressLookupTable(t *testing.T) {
+	_, err := rpc.ParseAddressLookupTable(table, make([]byte, 20))
+	assert.ErrorContains(t, err, "want at least 56")
+
+	_, err = rpc.ParseAddressLookupTable(table, make([]byte, 56))
+	assert.ErrorContains(t, err, "not an initialized lookup table")
+
+	data := make([]byte, 56+33)
+	data[0] = 1
+	_, err = rpc.ParseAddressLookupTable(table, data)
+	assert.ErrorContains(t, err, "not a multiple of 32")
+}
+
+func TestParseTokenAccount(t *testing.T) {
+	owner := solana.MustPublicKeyFromBase58("8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W")
+	account := rpctest.TokenAccount(solana.USDCMint, owner, 14951)
+
+	got, err := rpc.ParseTokenAccount(account.Data)
+	require.NoError(t, err)
+	assert.Equal(t, &rpc.TokenAccount{Mint: solana.USDCMint, Owner: owner, Amount: 14951}, got)
+
+	// Token-2022 accounts with extensions are longer.
+	_, err = rpc.ParseTokenAccount(append(account.Data, make([]byte, 20)...))
+	assert.NoError(t, err)
+
+	_, err = rpc.ParseTokenAccount(account.Data[:100])
+	assert.ErrorContains(t, err, "want at least 165")
+
+	_, err = rpc.ParseTokenAccount(make([]byte, rpc.TokenAccountLength))
+	assert.ErrorContains(t, err, "not initialized")
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	tx := signedTransaction(t)
+
+	t.Run("success", func(t *testing.T) {
+		result, err := c.SimulateTransaction(context.Background(), tx, nil)
+		require.NoError(t, err)
+		assert.Nil(t, result.Err)
+		assert.EqualValues(t, rpctest.DefaultSlot, result.Slot)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		srv.SetSimulationResult(&rpc.SimulationResult{
+			Err:           &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[0,{"Custom":6001}]}`)},
+			Logs:          []string{"Program log: Error: SlippageToleranceExceeded"},
+			Accounts:      []*rpc.Account{{Lamports: 42, Owner: solana.TokenProgramID, Data: []byte{1, 2, 3}}, nil},
+			UnitsConsumed: 12345,
+		})
+		defer srv.SetSimulationResult(nil)
+
+		result, err := c.SimulateTransaction(context.Background(), tx, &rpc.SimulateTransactionOptions{
+			ReplaceRecentBlockhash: true,
+			Commitment:             rpc.CommitmentProcessed,
+			Accounts:               []solana.PublicKey{solana.USDCMint, table},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.Err)
+		require.NotNil(t, result.Err.Custom)
+		assert.EqualValues(t, 6001, *result.Err.Custom)
+		assert.Equal(t, []string{"Program log: Error: SlippageToleranceExceeded"}, result.Logs)
+		assert.EqualValues(t, 12345, result.UnitsConsumed)
+		require.Len(t, result.Accounts, 2)
+		assert.Equal(t, &rpc.Account{Lamports: 42, Owner: solana.TokenProgramID, Data: []byte{1, 2, 3}}, result.Accounts[0])
+		assert.Nil(t, result.Accounts[1])
+
+		reqs := srv.Requests(rpctest.MethodSimulateTransaction)
+		var params []json.RawMessage
+		require.NoError(t, reqs[len(reqs)-1].DecodeParams(&params))
+		require.Len(t, params, 2)
+		assert.JSONEq(t, `{
+			"encoding": "base64",
+			"replaceRecentBlockhash": true,
+			"commitment": "processed",
+			"accounts": {"encoding": "base64", "addresses": ["`+solana.USDCMint.String()+`", "`+table.String()+`"]}
+		}`, string(params[1]))
+	})
+}
+
+func TestSendTransaction(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	tx := signedTransaction(t)
+
+	sig, err := c.SendTransaction(context.Background(), tx, &rpc.SendTransactionOptions{SkipPreflight: true, MaxRetries: new(uint64)})
+	require.NoError(t, err)
+	assert.Equal(t, tx.Signatures[0], sig)
+	sent := srv.Transactions()
+	require.Len(t, sent, 1)
+	assert.Equal(t, tx.Signatures, sent[0].Signatures)
+
+	var params []json.RawMessage
+	require.NoError(t, srv.Requests(rpctest.MethodSendTransaction)[0].DecodeParams(&params))
+	assert.JSONEq(t, `{"encoding":"base64","skipPreflight":true,"maxRetries":0}`, string(params[1]))
+}
+
+func TestGetSignatureStatuses(t *testing.T) {
+	srv := rpctest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	confirmed, failed, unknown := signedTransaction(t).Signatures[0], signedTransaction(t).Signatures[0], signedTransaction(t).Signatures[0]
+	srv.SetSignatureStatus(confirmed, &rpc.SignatureStatus{Slot: 10, ConfirmationStatus: rpc.CommitmentConfirmed})
+	srv.SetSignatureStatus(failed, &rpc.SignatureStatus{
+		Slot:               11,
+		Err:                &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[2,"InvalidAccountData"]}`)},
+		ConfirmationStatus: rpc.CommitmentProcessed,
+	})
+
+	statuses, err := c.GetSignatureStatuses(context.Background(), true, confirmed, failed, unknown)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+
+	assert.EqualValues(t, 10, statuses[0].Slot)
+	assert.Equal(t, rpc.CommitmentConfirmed, statuses[0].ConfirmationStatus)
+	assert.Nil(t, statuses[0].Err)
+
+	require.NotNil(t, statuses[1].Err)
+	assert.Equal(t, 2, statuses[1].Err.InstructionIndex)
+	assert.Equal(t, "InvalidAccountData", statuses[1].Err.InstructionError)
+
+	assert.Nil(t, statuses[2])
+
+	var params []json.RawMessage
+	require.NoError(t, srv.Requests(rpctest.MethodGetSignatureStatuses)[0].DecodeParams(&params))
+	assert.JSONEq(t, `{"searchTransactionHistory":true}`, string(params[1]))
+}
+
+func TestCallErrors(t *testing.T) {
+	t.Run("rpc error", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		srv.Handle(rpctest.MethodGetBlockHeight, func(json.RawMessage) (interface{}, *rpc.Error) {
+			return nil, &rpc.Error{Code: rpc.ErrorCodeNodeUnhealthy, Message: "Node is behind by 42 slots"}
+		})
+
+		_, err := srv.Client().GetBlockHeight(context.Background(), "")
+		var rpcErr *rpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, rpc.ErrorCodeNodeUnhealthy, rpcErr.Code)
+		assert.EqualError(t, err, "getBlockHeight failed: rpc error -32005: Node is behind by 42 slots")
+	})
+
+	t.Run("status code", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+
+		_, err := rpc.NewClient(rpc.WithAPIURL(srv.URL)).GetBlockHeight(context.Background(), "")
+		assert.EqualError(t, err, "unexpected status code: 429 from getBlockHeight: Too many requests")
+	})
+
+	t.Run("context", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := srv.Client().GetBlockHeight(ctx, "")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Known JSON-RPC error codes.
+const (
+	ErrorCodeBlockCleanedUp                   = -32001
+	ErrorCodeSendTransactionPreflightFailure  = -32002
+	ErrorCodeTransactionSignatureVerification = -32003
+	ErrorCodeNodeUnhealthy                    = -32005
+	ErrorCodeMethodNotFound                   = -32601
+	ErrorCodeInvalidParams                    = -32602
+)
+
+// Error is a JSON-RPC error returned by the node.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"` // e.g. the simulation result of a failed preflight
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// TransactionError is the error of a failed transaction, such as "BlockhashNotFound"
+// or {"InstructionError":[2,{"Custom":6001}]}.
+type TransactionError struct {
+	Kind             string          // e.g. "InstructionError" or "BlockhashNotFound"
+	InstructionIndex int             // index of the failed instruction, -1 unless Kind is "InstructionError"
+	InstructionError string          // e.g. "Custom" or "InvalidAccountData", set if Kind is "InstructionError"
+	Custom           *uint32         // custom program error code, e.g. 6001 for a Jupiter SlippageToleranceExceeded
+	Raw              json.RawMessage // error as returned by the node
+}
+
+// MarshalJSON implements json.Marshaler, returning the error as returned by the node.
+func (e TransactionError) MarshalJSON() ([]byte, error) {
+	if len(e.Raw) == 0 {
+		return json.Marshal(e.Kind)
+	}
+	return e.Raw, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *TransactionError) UnmarshalJSON(b []byte) error {
+	*e = TransactionError{InstructionIndex: -1, Raw: append(json.RawMessage(nil), b...)}
+
+	// Errors without details are plain strings.
+	if err := json.Unmarshal(b, &e.Kind); err == nil {
+		return nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return fmt.Errorf("invalid transaction error: %s", b)
+	}
+	var details json.RawMessage
+	for kind, v := range obj {
+		e.Kind, details = kind, v
+	}
+	if e.Kind != "InstructionError" {
+		return nil
+	}
+
+	var ie []json.RawMessage
+	if err := json.Unmarshal(details, &ie); err != nil || len(ie) != 2 {
+		return fmt.Errorf("invalid instruction error: %s", details)
+	}
+	if err := json.Unmarshal(ie[0], &e.InstructionIndex); err != nil {
+		return fmt.Errorf("invalid instruction error index: %w", err)
+	}
+	if err := json.Unmarshal(ie[1], &e.InstructionError); err == nil {
+		return nil
+	}
+	// Errors with details are objects, e.g. {"Custom":6001} or {"BorshIoError":"..."}.
+	var inner map[string]json.RawMessage
+	if err := json.Unmarshal(ie[1], &inner); err != nil {
+		return fmt.Errorf("invalid instruction error: %s", ie[1])
+	}
+	for name, v := range inner {
+		e.InstructionError = name
+		if name == "Custom" {
+			var code uint32
+			if err := json.Unmarshal(v, &code); err != nil {
+				return fmt.Errorf("invalid custom program error: %w", err)
+			}
+			e.Custom = &code
+		}
+	}
+
+	return nil
+}
+
+// Error implements the error interface.
+func (e *TransactionError) Error() string {
+	switch {
+	case e.Custom != nil:
+		return fmt.Sprintf("transaction failed: instruction %d: custom program error %d", e.InstructionIndex, *e.Custom)
+	case e.InstructionIndex >= 0:
+		return fmt.Sprintf("transaction failed: instruction %d: %s", e.InstructionIndex, e.InstructionError)
+	case e.Kind != "":
+		return "transaction failed: " + e.Kind
+	default:
+		return "transaction failed: " + string(e.Raw)
+	}
+}
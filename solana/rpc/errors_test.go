@@ -0,0 +1,72 @@
+package rpc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionError(t *testing.T) {
+	custom := uint32(6001)
+
+	tests := []struct {
+		name string
+		json string
+		want rpc.TransactionError
+		msg  string
+	}{
+		{
+			name: "plain",
+			json: `"BlockhashNotFound"`,
+			want: rpc.TransactionError{Kind: "BlockhashNotFound", InstructionIndex: -1},
+			msg:  "transaction failed: BlockhashNotFound",
+		},
+		{
+			name: "with details",
+			json: `{"InsufficientFundsForRent":{"account_index":2}}`,
+			want: rpc.TransactionError{Kind: "InsufficientFundsForRent", InstructionIndex: -1},
+			msg:  "transaction failed: InsufficientFundsForRent",
+		},
+		{
+			name: "instruction error",
+			json: `{"InstructionError":[1,"InvalidAccountData"]}`,
+			want: rpc.TransactionError{Kind: "InstructionError", InstructionIndex: 1, InstructionError: "InvalidAccountData"},
+			msg:  "transaction failed: instruction 1: InvalidAccountData",
+		},
+		{
+			name: "custom program error",
+			json: `{"InstructionError":[3,{"Custom":6001}]}`,
+			want: rpc.TransactionError{Kind: "InstructionError", InstructionIndex: 3, InstructionError: "Custom", Custom: &custom},
+			msg:  "transaction failed: instruction 3: custom program error 6001",
+		},
+		{
+			name: "instruction error with details",
+			json: `{"InstructionError":[0,{"BorshIoError":"Unknown"}]}`,
+			want: rpc.TransactionError{Kind: "InstructionError", InstructionIndex: 0, InstructionError: "BorshIoError"},
+			msg:  "transaction failed: instruction 0: BorshIoError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got rpc.TransactionError
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &got))
+			tt.want.Raw = json.RawMessage(tt.json)
+			assert.Equal(t, tt.want, got)
+			assert.EqualError(t, &got, tt.msg)
+
+			b, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.json, string(b))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var got rpc.TransactionError
+		assert.Error(t, json.Unmarshal([]byte(`{"InstructionError":[0]}`), &got))
+		assert.Error(t, json.Unmarshal([]byte(`42`), &got))
+	})
+}
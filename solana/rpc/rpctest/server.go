@@ -0,0 +1,436 @@
+// Package rpctest provides an in-process fake of the Solana JSON-RPC API for tests.
+//
+// The fake serves the methods used by rpc.Client with sensible defaults. Tests set
+// the block height, accounts, signature statuses and simulation results, script
+// any method, and inspect the requests and transactions received:
+//
+//	srv := rpctest.NewServer()
+//	defer srv.Close()
+//
+//	srv.SetAddressLookupTable(table)
+//	srv.SetSignatureStatus(sig, &rpc.SignatureStatus{ConfirmationStatus: rpc.CommitmentConfirmed})
+//
+//	c := srv.Client()
+//	tables, err := c.GetAddressLookupTables(ctx, table.Key)
+package rpctest
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+)
+
+// Methods served by the fake.
+const (
+	MethodGetLatestBlockhash   = "getLatestBlockhash"
+	MethodGetBlockHeight       = "getBlockHeight"
+	MethodGetAccountInfo       = "getAccountInfo"
+	MethodSimulateTransaction  = "simulateTransaction"
+	MethodSendTransaction      = "sendTransaction"
+	MethodGetSignatureStatuses = "getSignatureStatuses"
+)
+
+// DefaultBlockhash is the blockhash returned by getLatestBlockhash.
+var DefaultBlockhash = solana.MustHashFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N")
+
+// DefaultBlockHeight is the initial block height of the fake.
+const DefaultBlockHeight = 900
+
+// BlockhashValidity is the number of blocks a blockhash returned by getLatestBlockhash is valid for.
+const BlockhashValidity = 150
+
+// DefaultSlot is the slot of the responses of the fake.
+const DefaultSlot = 1000
+
+type (
+	// Server is a fake Solana JSON-RPC server backed by httptest.Server.
+	Server struct {
+		URL string // URL of the fake, to be used with rpc.WithAPIURL
+
+		srv *httptest.Server
+
+		mu           sync.Mutex
+		blockHeight  uint64
+		accounts     map[solana.PublicKey]*rpc.Account
+		statuses     map[solana.Signature]*rpc.SignatureStatus
+		simulation   *rpc.SimulationResult
+		simulated    map[solana.PublicKey]*rpc.Account
+		handlers     map[string]HandlerFunc
+		requests     []Request
+		transactions []*solana.Transaction
+	}
+
+	// HandlerFunc handles the params of a JSON-RPC method, returning its result or error.
+	HandlerFunc func(params json.RawMessage) (interface{}, *rpc.Error)
+
+	// Request is a JSON-RPC request received by the fake.
+	Request struct {
+		Method string
+		Params json.RawMessage
+	}
+
+	request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+
+	response struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result,omitempty"`
+		Error   *rpc.Error      `json:"error,omitempty"`
+	}
+
+	contextValue struct {
+		Context rpc.Context `json:"context"`
+		Value   interface{} `json:"value"`
+	}
+)
+
+// NewServer starts and returns a new fake RPC server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		blockHeight: DefaultBlockHeight,
+		accounts:    make(map[solana.PublicKey]*rpc.Account),
+		statuses:    make(map[solana.Signature]*rpc.SignatureStatus),
+		simulated:   make(map[solana.PublicKey]*rpc.Account),
+		handlers:    make(map[string]HandlerFunc),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns an rpc.Client talking to the fake, configured with the given additional options.
+func (s *Server) Client(opts ...rpc.ClientOption) *rpc.Client {
+	return rpc.NewClient(append([]rpc.ClientOption{
+		rpc.WithHTTPClient(s.srv.Client()),
+		rpc.WithAPIURL(s.URL),
+	}, opts...)...)
+}
+
+// SetBlockHeight sets the block height returned by getBlockHeight.
+// The blockhash returned by getLatestBlockhash is valid for BlockhashValidity blocks from it.
+func (s *Server) SetBlockHeight(height uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blockHeight = height
+}
+
+// SetAccount makes getAccountInfo return the given account at the given address, or no account if nil.
+func (s *Server) SetAccount(address solana.PublicKey, account *rpc.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if account == nil {
+		delete(s.accounts, address)
+		return
+	}
+	s.accounts[address] = account
+}
+
+// SetAddressLookupTable stores the address lookup table as an account, see rpc.Client.GetAddressLookupTable.
+func (s *Server) SetAddressLookupTable(table solana.AddressLookupTable) {
+	data := make([]byte, 56, 56+len(table.Addresses)*solana.PublicKeyLength)
+	binary.LittleEndian.PutUint32(data, 1) // initialized lookup table
+	binary.LittleEndian.PutUint64(data[4:], ^uint64(0))
+	for _, k := range table.Addresses {
+		data = append(data, k[:]...)
+	}
+
+	s.SetAccount(table.Key, &rpc.Account{
+		Lamports: 1,
+		Owner:    solana.AddressLookupTableProgramID,
+		Data:     data,
+	})
+}
+
+// TokenAccountRent is the rent exempt balance of the accounts returned by TokenAccount.
+const TokenAccountRent = 2039280
+
+// TokenAccount returns an initialized SPL Token account holding amount of the mint, to be set
+// with SetAccount or returned in a simulation result.
+func TokenAccount(mint, owner solana.PublicKey, amount uint64) *rpc.Account {
+	data := make([]byte, rpc.TokenAccountLength)
+	copy(data[0:32], mint[:])
+	copy(data[32:64], owner[:])
+	binary.LittleEndian.PutUint64(data[64:72], amount)
+	data[108] = 1 // initialized
+
+	return &rpc.Account{
+		Lamports: TokenAccountRent,
+		Owner:    solana.TokenProgramID,
+		Data:     data,
+	}
+}
+
+// SetSignatureStatus makes getSignatureStatuses return the given status for the signature, or nil if status is nil.
+func (s *Server) SetSignatureStatus(signature solana.Signature, status *rpc.SignatureStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status == nil {
+		delete(s.statuses, signature)
+		return
+	}
+	s.statuses[signature] = status
+}
+
+// SetSimulationResult makes simulateTransaction return the given result instead of a successful simulation without logs.
+// Unless the result has accounts, the accounts requested are returned as set with SetSimulatedAccount.
+func (s *Server) SetSimulationResult(result *rpc.SimulationResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.simulation = result
+}
+
+// SetSimulatedAccount sets the state of the account after a simulation, nil if the simulation closes it,
+// returned by simulateTransaction when the account is requested. Accounts without a simulated state
+// are returned as set with SetAccount.
+func (s *Server) SetSimulatedAccount(address solana.PublicKey, account *rpc.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.simulated[address] = account
+}
+
+// Handle replaces the handler of the given method.
+func (s *Server) Handle(method string, h HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method] = h
+}
+
+// Requests returns the requests received for the given method, or all requests if method is empty.
+func (s *Server) Requests(method string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reqs []Request
+	for _, r := range s.requests {
+		if method == "" || r.Method == method {
+			reqs = append(reqs, r)
+		}
+	}
+
+	return reqs
+}
+
+// Transactions returns the transactions received by sendTransaction.
+func (s *Server) Transactions() []*solana.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*solana.Transaction(nil), s.transactions...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, response{Error: &rpc.Error{Code: -32700, Message: "Parse error"}})
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: req.Method, Params: req.Params})
+	handler := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	if handler == nil {
+		handler = s.defaultHandler(req.Method)
+	}
+	result, rpcErr := handler(req.Params)
+	resp := response{ID: req.ID, Result: result, Error: rpcErr}
+	if rpcErr == nil && result == nil {
+		// A null result, e.g. of getAccountInfo for a missing account, must still be sent.
+		resp.Result = json.RawMessage("null")
+	}
+	writeJSON(w, resp)
+}
+
+// defaultHandler returns the built-in handler of the method.
+func (s *Server) defaultHandler(method string) HandlerFunc {
+	switch method {
+	case MethodGetLatestBlockhash:
+		return s.getLatestBlockhash
+	case MethodGetBlockHeight:
+		return s.getBlockHeight
+	case MethodGetAccountInfo:
+		return s.getAccountInfo
+	case MethodSimulateTransaction:
+		return s.simulateTransaction
+	case MethodSendTransaction:
+		return s.sendTransaction
+	case MethodGetSignatureStatuses:
+		return s.getSignatureStatuses
+	default:
+		return func(json.RawMessage) (interface{}, *rpc.Error) {
+			return nil, &rpc.Error{Code: rpc.ErrorCodeMethodNotFound, Message: "Method not found"}
+		}
+	}
+}
+
+func (s *Server) getLatestBlockhash(json.RawMessage) (interface{}, *rpc.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withContext(map[string]interface{}{
+		"blockhash":            DefaultBlockhash,
+		"lastValidBlockHeight": s.blockHeight + BlockhashValidity,
+	}), nil
+}
+
+func (s *Server) getBlockHeight(json.RawMessage) (interface{}, *rpc.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.blockHeight, nil
+}
+
+func (s *Server) getAccountInfo(params json.RawMessage) (interface{}, *rpc.Error) {
+	var args []json.RawMessage
+	var address solana.PublicKey
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return nil, invalidParams("missing address")
+	}
+	if err := json.Unmarshal(args[0], &address); err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withContext(s.accounts[address]), nil
+}
+
+func (s *Server) simulateTransaction(params json.RawMessage) (interface{}, *rpc.Error) {
+	if _, rpcErr := decodeTransaction(params); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	var args []json.RawMessage
+	var config struct {
+		Accounts *struct {
+			Addresses []solana.PublicKey `json:"addresses"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(params, &args); err == nil && len(args) > 1 {
+		if err := json.Unmarshal(args[1], &config); err != nil {
+			return nil, invalidParams(err.Error())
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := rpc.SimulationResult{Logs: []string{}}
+	if s.simulation != nil {
+		result = *s.simulation
+	}
+	if result.Accounts == nil && config.Accounts != nil {
+		for _, address := range config.Accounts.Addresses {
+			account, ok := s.simulated[address]
+			if !ok {
+				account = s.accounts[address]
+			}
+			result.Accounts = append(result.Accounts, account)
+		}
+	}
+
+	return withContext(&result), nil
+}
+
+func (s *Server) sendTransaction(params json.RawMessage) (interface{}, *rpc.Error) {
+	tx, rpcErr := decodeTransaction(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transactions = append(s.transactions, tx)
+	return tx.Signatures[0], nil
+}
+
+func (s *Server) getSignatureStatuses(params json.RawMessage) (interface{}, *rpc.Error) {
+	var args []json.RawMessage
+	var signatures []solana.Signature
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return nil, invalidParams("missing signatures")
+	}
+	if err := json.Unmarshal(args[0], &signatures); err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]*rpc.SignatureStatus, len(signatures))
+	for i, sig := range signatures {
+		statuses[i] = s.statuses[sig]
+	}
+	return withContext(statuses), nil
+}
+
+// decodeTransaction decodes the base64 transaction passed as first param.
+func decodeTransaction(params json.RawMessage) (*solana.Transaction, *rpc.Error) {
+	var args []json.RawMessage
+	var encoded string
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return nil, invalidParams("missing transaction")
+	}
+	if err := json.Unmarshal(args[0], &encoded); err != nil {
+		return nil, invalidParams(err.Error())
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalidParams("invalid base64 transaction: " + err.Error())
+	}
+	tx, err := solana.TransactionFromBytes(b)
+	if err != nil {
+		return nil, invalidParams("failed to deserialize transaction: " + err.Error())
+	}
+	if len(tx.Signatures) == 0 {
+		return nil, invalidParams("transaction has no signatures")
+	}
+
+	return tx, nil
+}
+
+func invalidParams(msg string) *rpc.Error {
+	return &rpc.Error{Code: rpc.ErrorCodeInvalidParams, Message: "Invalid params: " + msg}
+}
+
+func withContext(value interface{}) contextValue {
+	return contextValue{Context: rpc.Context{Slot: DefaultSlot}, Value: value}
+}
+
+func writeJSON(w http.ResponseWriter, v response) {
+	v.JSONRPC = "2.0"
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// DecodeParams decodes the params of the request into v, usually a []json.RawMessage.
+func (r Request) DecodeParams(v interface{}) error {
+	return json.Unmarshal(r.Params, v)
+}
@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qiruos/jupiter/solana"
+)
+
+// Commitment is how finalized a block must be to be considered by a request.
+type Commitment string
+
+// Commitment levels, from the fastest to the safest.
+const (
+	CommitmentProcessed Commitment = "processed"
+	CommitmentConfirmed Commitment = "confirmed"
+	CommitmentFinalized Commitment = "finalized"
+)
+
+type (
+	// Context is the context of a response.
+	Context struct {
+		Slot uint64 `json:"slot"` // slot at which the request was evaluated
+	}
+
+	// LatestBlockhash is the result of GetLatestBlockhash.
+	LatestBlockhash struct {
+		Blockhash            solana.Hash `json:"blockhash"`
+		LastValidBlockHeight uint64      `json:"lastValidBlockHeight"` // last block height at which the blockhash is valid
+		Slot                 uint64      `json:"-"`                    // slot at which the blockhash was fetched
+	}
+
+	// Account is an account fetched from the cluster.
+	Account struct {
+		Lamports   uint64
+		Owner      solana.PublicKey
+		Data       []byte
+		Executable bool
+		RentEpoch  uint64
+	}
+
+	// SimulateTransactionOptions configures SimulateTransaction.
+	SimulateTransactionOptions struct {
+		SigVerify              bool               // verify the signatures, conflicts with ReplaceRecentBlockhash
+		ReplaceRecentBlockhash bool               // replace the blockhash of the transaction with the latest one, so unsigned or expired transactions can be simulated
+		Commitment             Commitment         // commitment of the bank the transaction is simulated against
+		Accounts               []solana.PublicKey // accounts returned in SimulationResult.Accounts after the simulation
+	}
+
+	// SimulationResult is the result of SimulateTransaction.
+	SimulationResult struct {
+		Err           *TransactionError `json:"err"` // nil if the transaction succeeded
+		Logs          []string          `json:"logs"`
+		Accounts      []*Account        `json:"accounts"`      // state of SimulateTransactionOptions.Accounts after the simulation, nil entries for missing accounts
+		UnitsConsumed uint64            `json:"unitsConsumed"` // compute units consumed by the transaction
+		Slot          uint64            `json:"-"`             // slot at which the transaction was simulated
+	}
+
+	// SendTransactionOptions configures SendTransaction.
+	SendTransactionOptions struct {
+		SkipPreflight       bool       // skip the preflight simulation
+		PreflightCommitment Commitment // commitment of the preflight simulation, finalized by default
+		MaxRetries          *uint64    // number of times the node rebroadcasts the transaction, the node default if nil
+	}
+
+	// SignatureStatus is the status of a transaction, see GetSignatureStatuses.
+	SignatureStatus struct {
+		Slot               uint64            `json:"slot"`               // slot the transaction was processed in
+		Confirmations      *uint64           `json:"confirmations"`      // nil once the block is finalized
+		Err                *TransactionError `json:"err"`                // nil if the transaction succeeded
+		ConfirmationStatus Commitment        `json:"confirmationStatus"` // commitment reached by the transaction
+	}
+
+	commitmentConfig struct {
+		Commitment Commitment `json:"commitment,omitempty"`
+	}
+
+	accountConfig struct {
+		Encoding   string     `json:"encoding"`
+		Commitment Commitment `json:"commitment,omitempty"`
+	}
+
+	simulateConfig struct {
+		Encoding               string                  `json:"encoding"`
+		SigVerify              bool                    `json:"sigVerify,omitempty"`
+		ReplaceRecentBlockhash bool                    `json:"replaceRecentBlockhash,omitempty"`
+		Commitment             Commitment              `json:"commitment,omitempty"`
+		Accounts               *simulateAccountsConfig `json:"accounts,omitempty"`
+	}
+
+	simulateAccountsConfig struct {
+		Encoding  string             `json:"encoding"`
+		Addresses []solana.PublicKey `json:"addresses"`
+	}
+
+	sendConfig struct {
+		Encoding            string     `json:"encoding"`
+		SkipPreflight       bool       `json:"skipPreflight,omitempty"`
+		PreflightCommitment Commitment `json:"preflightCommitment,omitempty"`
+		MaxRetries          *uint64    `json:"maxRetries,omitempty"`
+	}
+
+	signatureStatusesConfig struct {
+		SearchTransactionHistory bool `json:"searchTransactionHistory"`
+	}
+
+	// accountJSON is the JSON representation of an account encoded in base64.
+	accountJSON struct {
+		Lamports   uint64           `json:"lamports"`
+		Owner      solana.PublicKey `json:"owner"`
+		Data       [2]string        `json:"data"` // base64 data and "base64"
+		Executable bool             `json:"executable"`
+		RentEpoch  uint64           `json:"rentEpoch"`
+	}
+)
+
+// MarshalJSON implements json.Marshaler, encoding the data in base64.
+func (a Account) MarshalJSON() ([]byte, error) {
+	return json.Marshal(accountJSON{
+		Lamports:   a.Lamports,
+		Owner:      a.Owner,
+		Data:       [2]string{base64.StdEncoding.EncodeToString(a.Data), "base64"},
+		Executable: a.Executable,
+		RentEpoch:  a.RentEpoch,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Only base64 encoded data is supported.
+func (a *Account) UnmarshalJSON(b []byte) error {
+	var v accountJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Data[1] != "base64" {
+		return fmt.Errorf("unsupported account data encoding %q", v.Data[1])
+	}
+
+	data, err := base64.StdEncoding.DecodeString(v.Data[0])
+	if err != nil {
+		return fmt.Errorf("invalid account data: %w", err)
+	}
+
+	*a = Account{
+		Lamports:   v.Lamports,
+		Owner:      v.Owner,
+		Data:       data,
+		Executable: v.Executable,
+		RentEpoch:  v.RentEpoch,
+	}
+	return nil
+}
+
+// addressLookupTableMetaSize is the size of the header of an address lookup table account,
+// followed by the addresses.
+const addressLookupTableMetaSize = 56
+
+// ParseAddressLookupTable parses the data of the address lookup table account at the given address.
+func ParseAddressLookupTable(address solana.PublicKey, data []byte) (solana.AddressLookupTable, error) {
+	if len(data) < addressLookupTableMetaSize {
+		return solana.AddressLookupTable{}, fmt.Errorf("account data is %d bytes, want at least %d", len(data), addressLookupTableMetaSize)
+	}
+	if kind := binary.LittleEndian.Uint32(data); kind != 1 {
+		return solana.AddressLookupTable{}, fmt.Errorf("account is not an initialized lookup table, type is %d", kind)
+	}
+
+	addresses := data[addressLookupTableMetaSize:]
+	if len(addresses)%solana.PublicKeyLength != 0 {
+		return solana.AddressLookupTable{}, fmt.Errorf("addresses length %d is not a multiple of %d", len(addresses), solana.PublicKeyLength)
+	}
+
+	table := solana.AddressLookupTable{Key: address}
+	for i := 0; i < len(addresses); i += solana.PublicKeyLength {
+		var k solana.PublicKey
+		copy(k[:], addresses[i:])
+		table.Addresses = append(table.Addresses, k)
+	}
+
+	return table, nil
+}
+
+// TokenAccountLength is the length of an SPL Token account. Token-2022 accounts with extensions are longer.
+const TokenAccountLength = 165
+
+// tokenAccountStateOffset is the offset of the state of a token account, after the mint, the owner,
+// the amount and the optional delegate.
+const tokenAccountStateOffset = 108
+
+// TokenAccount is the state of an SPL Token or Token-2022 token account, without its extensions.
+type TokenAccount struct {
+	Mint   solana.PublicKey
+	Owner  solana.PublicKey
+	Amount uint64
+}
+
+// ParseTokenAccount parses the data of an initialized SPL Token or Token-2022 token account.
+func ParseTokenAccount(data []byte) (*TokenAccount, error) {
+	if len(data) < TokenAccountLength {
+		return nil, fmt.Errorf("account data is %d bytes, want at least %d", len(data), TokenAccountLength)
+	}
+	if state := data[tokenAccountStateOffset]; state == 0 {
+		return nil, fmt.Errorf("token account is not initialized")
+	}
+
+	var a TokenAccount
+	copy(a.Mint[:], data[0:32])
+	copy(a.Owner[:], data[32:64])
+	a.Amount = binary.LittleEndian.Uint64(data[64:72])
+
+	return &a, nil
+}
@@ -0,0 +1,144 @@
+package solana
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// errShortBuffer is returned when a serialized transaction or message ends unexpectedly.
+var errShortBuffer = errors.New("unexpected end of data")
+
+// appendShortVecLen appends n encoded as a compact-u16, the length prefix used by Solana for arrays.
+// It fails if n does not fit in a compact-u16.
+func appendShortVecLen(b []byte, n int) ([]byte, error) {
+	if n < 0 || n > 0xffff {
+		return nil, fmt.Errorf("length %d does not fit in a compact-u16", n)
+	}
+	v := uint16(n)
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(b, c), nil
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+// appendShortVec appends the compact-u16 prefixed byte array v.
+func appendShortVec(b, v []byte) ([]byte, error) {
+	b, err := appendShortVecLen(b, len(v))
+	if err != nil {
+		return nil, err
+	}
+	return append(b, v...), nil
+}
+
+// decoder reads the binary encoding of transactions and messages.
+type decoder struct {
+	b   []byte
+	pos int
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.b) {
+		return 0, errShortBuffer
+	}
+	c := d.b[d.pos]
+	d.pos++
+	return c, nil
+}
+
+func (d *decoder) bytes(n int) ([]byte, error) {
+	if n > len(d.b)-d.pos {
+		return nil, errShortBuffer
+	}
+	b := d.b[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// shortVecLen reads a compact-u16 length.
+func (d *decoder) shortVecLen() (int, error) {
+	var n int
+	for i := 0; i < 3; i++ {
+		c, err := d.byte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(c&0x7f) << (7 * i)
+		if c&0x80 == 0 {
+			if n > 0xffff {
+				return 0, fmt.Errorf("compact-u16 length %d overflows", n)
+			}
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("compact-u16 length is too long")
+}
+
+// vecLen reads the compact-u16 length of an array whose elements are encoded in at least size bytes,
+// checking the data is long enough to hold them before they are allocated.
+func (d *decoder) vecLen(size int) (int, error) {
+	n, err := d.shortVecLen()
+	if err != nil {
+		return 0, err
+	}
+	if n*size > d.remaining() {
+		return 0, errShortBuffer
+	}
+	return n, nil
+}
+
+func (d *decoder) publicKey() (PublicKey, error) {
+	b, err := d.bytes(PublicKeyLength)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	var k PublicKey
+	copy(k[:], b)
+	return k, nil
+}
+
+// byteVec reads a compact-u16 prefixed byte array.
+func (d *decoder) byteVec() ([]byte, error) {
+	n, err := d.shortVecLen()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	b, err := d.bytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(b), nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	b, err := d.bytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// remaining returns the number of bytes left to read.
+func (d *decoder) remaining() int {
+	return len(d.b) - d.pos
+}
@@ -0,0 +1,135 @@
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Signer signs transaction messages on behalf of an account.
+type Signer interface {
+	// PublicKey returns the account the signer signs for.
+	PublicKey() PublicKey
+	// Sign returns the ed25519 signature of the serialized message.
+	Sign(ctx context.Context, message []byte) (Signature, error)
+}
+
+// Keypair is an in-memory ed25519 keypair. It implements Signer.
+type Keypair struct {
+	privateKey ed25519.PrivateKey
+	publicKey  PublicKey
+}
+
+// NewKeypair returns a new random keypair.
+func NewKeypair() (*Keypair, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	return newKeypair(priv), nil
+}
+
+// KeypairFromSeed returns the keypair derived from the given 32-byte seed.
+func KeypairFromSeed(seed []byte) (*Keypair, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid seed length %d, want %d", len(seed), ed25519.SeedSize)
+	}
+	return newKeypair(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// KeypairFromBytes returns the keypair of the given 64-byte secret key, the seed followed by the public key,
+// as stored in Solana CLI keypair files.
+func KeypairFromBytes(b []byte) (*Keypair, error) {
+	if len(b) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid secret key length %d, want %d", len(b), ed25519.PrivateKeySize)
+	}
+
+	k := newKeypair(ed25519.NewKeyFromSeed(b[:ed25519.SeedSize]))
+	if string(k.publicKey[:]) != string(b[ed25519.SeedSize:]) {
+		return nil, fmt.Errorf("secret key does not match its public key")
+	}
+
+	return k, nil
+}
+
+// KeypairFromBase58 returns the keypair of the given base58 encoded 64-byte secret key, as exported by wallets.
+func KeypairFromBase58(s string) (*Keypair, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	return KeypairFromBytes(b)
+}
+
+// LoadKeypairFile loads a keypair from a Solana CLI keypair file, a JSON array of the 64 secret key bytes.
+func LoadKeypairFile(path string) (*Keypair, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keypair file: %w", err)
+	}
+
+	// The bytes are encoded as an array of numbers, not as a base64 string.
+	var ints []uint8
+	if err := json.Unmarshal(data, &ints); err != nil {
+		return nil, fmt.Errorf("failed to parse keypair file %s: %w", path, err)
+	}
+
+	k, err := KeypairFromBytes(ints)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keypair file %s: %w", path, err)
+	}
+
+	return k, nil
+}
+
+func newKeypair(priv ed25519.PrivateKey) *Keypair {
+	k := &Keypair{privateKey: priv}
+	copy(k.publicKey[:], priv.Public().(ed25519.PublicKey))
+	return k
+}
+
+// PublicKey implements Signer.
+func (k *Keypair) PublicKey() PublicKey {
+	return k.publicKey
+}
+
+// Sign implements Signer.
+func (k *Keypair) Sign(_ context.Context, message []byte) (Signature, error) {
+	var sig Signature
+	copy(sig[:], ed25519.Sign(k.privateKey, message))
+	return sig, nil
+}
+
+// SignFunc signs a serialized message, see NewRemoteSigner.
+type SignFunc func(ctx context.Context, message []byte) (Signature, error)
+
+// remoteSigner delegates signing to a SignFunc.
+type remoteSigner struct {
+	publicKey PublicKey
+	sign      SignFunc
+}
+
+// NewRemoteSigner returns a Signer for the given account that delegates signing to fn,
+// for instance to call a KMS, an HSM or a wallet service.
+// Signatures are verified against the public key when signing transactions.
+func NewRemoteSigner(publicKey PublicKey, fn SignFunc) Signer {
+	return &remoteSigner{publicKey: publicKey, sign: fn}
+}
+
+// PublicKey implements Signer.
+func (s *remoteSigner) PublicKey() PublicKey {
+	return s.publicKey
+}
+
+// Sign implements Signer.
+func (s *remoteSigner) Sign(ctx context.Context, message []byte) (Signature, error) {
+	return s.sign(ctx, message)
+}
+
+// Verify reports whether sig is a valid signature of message by the key.
+func (k PublicKey) Verify(message []byte, sig Signature) bool {
+	return ed25519.Verify(k[:], message, sig[:])
+}
@@ -0,0 +1,162 @@
+package solana_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeypair(t *testing.T) {
+	// RFC 8032 test vector 1.
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	pub, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+
+	t.Run("from seed", func(t *testing.T) {
+		k, err := solana.KeypairFromSeed(seed)
+		require.NoError(t, err)
+		assert.Equal(t, pub, k.PublicKey().Bytes())
+
+		sig, err := k.Sign(context.Background(), []byte("message"))
+		require.NoError(t, err)
+		assert.True(t, k.PublicKey().Verify([]byte("message"), sig))
+		assert.False(t, k.PublicKey().Verify([]byte("other"), sig))
+
+		_, err = solana.KeypairFromSeed(seed[:31])
+		assert.Error(t, err)
+	})
+
+	t.Run("from bytes", func(t *testing.T) {
+		k, err := solana.KeypairFromBytes(append(append([]byte(nil), seed...), pub...))
+		require.NoError(t, err)
+		assert.Equal(t, pub, k.PublicKey().Bytes())
+
+		fromBase58, err := solana.KeypairFromBase58(solana.EncodeBase58(append(append([]byte(nil), seed...), pub...)))
+		require.NoError(t, err)
+		assert.Equal(t, k.PublicKey(), fromBase58.PublicKey())
+
+		_, err = solana.KeypairFromBytes(append(append([]byte(nil), seed...), make([]byte, 32)...))
+		assert.Error(t, err)
+	})
+
+	t.Run("solana cli file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "id.json")
+		var ints []int
+		for _, b := range append(append([]byte(nil), seed...), pub...) {
+			ints = append(ints, int(b))
+		}
+		content, err := json.Marshal(ints)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, content, 0o600))
+
+		k, err := solana.LoadKeypairFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, pub, k.PublicKey().Bytes())
+
+		require.NoError(t, os.WriteFile(path, []byte(`[1,2,3]`), 0o600))
+		_, err = solana.LoadKeypairFile(path)
+		assert.Error(t, err)
+
+		_, err = solana.LoadKeypairFile(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		a, err := solana.NewKeypair()
+		require.NoError(t, err)
+		b, err := solana.NewKeypair()
+		require.NoError(t, err)
+		assert.NotEqual(t, a.PublicKey(), b.PublicKey())
+	})
+}
+
+// signedTransaction returns a transaction requiring the signatures of the given accounts.
+func signedTransaction(signers ...solana.PublicKey) *solana.Transaction {
+	return &solana.Transaction{
+		Signatures: make([]solana.Signature, len(signers)),
+		Message: solana.Message{
+			Version:         solana.MessageVersionV0,
+			Header:          solana.MessageHeader{NumRequiredSignatures: uint8(len(signers)), NumReadonlyUnsignedAccounts: 1},
+			AccountKeys:     append(signers, solana.SystemProgramID),
+			RecentBlockhash: blockhash,
+			Instructions: []solana.CompiledInstruction{
+				{ProgramIDIndex: uint8(len(signers)), Accounts: []uint8{0, 1}, Data: []byte{2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+			},
+		},
+	}
+}
+
+func TestTransactionSign(t *testing.T) {
+	ctx := context.Background()
+
+	payer, err := solana.NewKeypair()
+	require.NoError(t, err)
+	other, err := solana.NewKeypair()
+	require.NoError(t, err)
+
+	t.Run("signatures are placed in signer order", func(t *testing.T) {
+		tx := signedTransaction(payer.PublicKey(), other.PublicKey())
+
+		require.NoError(t, tx.Sign(ctx, other))
+		assert.True(t, tx.Signatures[0].IsZero())
+		assert.False(t, tx.Signatures[1].IsZero())
+		assert.Error(t, tx.Verify())
+
+		require.NoError(t, tx.Sign(ctx, payer))
+		require.NoError(t, tx.Verify())
+
+		b, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		decoded, err := solana.TransactionFromBytes(b)
+		require.NoError(t, err)
+		require.NoError(t, decoded.Verify())
+	})
+
+	t.Run("unknown signer", func(t *testing.T) {
+		tx := signedTransaction(payer.PublicKey())
+		assert.Error(t, tx.Sign(ctx, other))
+	})
+
+	t.Run("tampered message", func(t *testing.T) {
+		tx := signedTransaction(payer.PublicKey())
+		require.NoError(t, tx.Sign(ctx, payer))
+		tx.Message.Instructions[0].Data[4] = 2
+		assert.Error(t, tx.Verify())
+	})
+
+	t.Run("remote signer", func(t *testing.T) {
+		var signed []byte
+		remote := solana.NewRemoteSigner(payer.PublicKey(), func(ctx context.Context, message []byte) (solana.Signature, error) {
+			signed = message
+			return payer.Sign(ctx, message)
+		})
+
+		tx := signedTransaction(payer.PublicKey())
+		require.NoError(t, tx.Sign(ctx, remote))
+		require.NoError(t, tx.Verify())
+
+		msg, err := tx.Message.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, msg, signed)
+	})
+
+	t.Run("remote signer errors", func(t *testing.T) {
+		failure := errors.New("hsm unavailable")
+		failing := solana.NewRemoteSigner(payer.PublicKey(), func(context.Context, []byte) (solana.Signature, error) {
+			return solana.Signature{}, failure
+		})
+		wrongKey := solana.NewRemoteSigner(payer.PublicKey(), other.Sign)
+
+		tx := signedTransaction(payer.PublicKey())
+		assert.ErrorIs(t, tx.Sign(ctx, failing), failure)
+		assert.EqualError(t, tx.Sign(ctx, wrongKey), "invalid signature for "+payer.PublicKey().String())
+		assert.True(t, tx.Signatures[0].IsZero())
+	})
+}
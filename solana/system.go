@@ -0,0 +1,118 @@
+package solana
+
+import "encoding/binary"
+
+// System program instruction discriminators.
+const (
+	systemCreateAccount = 0
+	systemAssign        = 1
+	systemTransfer      = 2
+	systemAllocate      = 8
+)
+
+// SystemCreateAccount creates a new account funded by From.
+type SystemCreateAccount struct {
+	From       PublicKey
+	NewAccount PublicKey
+	Lamports   uint64
+	Space      uint64
+	Owner      PublicKey
+}
+
+// SystemAssign assigns an account to a program.
+type SystemAssign struct {
+	Account PublicKey
+	Owner   PublicKey
+}
+
+// SystemTransfer transfers lamports between accounts.
+type SystemTransfer struct {
+	From     PublicKey
+	To       PublicKey
+	Lamports uint64
+}
+
+// SystemAllocate allocates space to an account.
+type SystemAllocate struct {
+	Account PublicKey
+	Space   uint64
+}
+
+// ProgramID implements DecodedInstruction.
+func (*SystemCreateAccount) ProgramID() PublicKey { return SystemProgramID }
+
+// Name implements DecodedInstruction.
+func (*SystemCreateAccount) Name() string { return "CreateAccount" }
+
+// ProgramID implements DecodedInstruction.
+func (*SystemAssign) ProgramID() PublicKey { return SystemProgramID }
+
+// Name implements DecodedInstruction.
+func (*SystemAssign) Name() string { return "Assign" }
+
+// ProgramID implements DecodedInstruction.
+func (*SystemTransfer) ProgramID() PublicKey { return SystemProgramID }
+
+// Name implements DecodedInstruction.
+func (*SystemTransfer) Name() string { return "Transfer" }
+
+// ProgramID implements DecodedInstruction.
+func (*SystemAllocate) ProgramID() PublicKey { return SystemProgramID }
+
+// Name implements DecodedInstruction.
+func (*SystemAllocate) Name() string { return "Allocate" }
+
+// Instruction returns the instruction.
+func (i *SystemTransfer) Instruction() Instruction {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, systemTransfer)
+	binary.LittleEndian.PutUint64(data[4:], i.Lamports)
+	return Instruction{
+		ProgramID: SystemProgramID,
+		Accounts:  []AccountMeta{Meta(i.From, true, true), Meta(i.To, false, true)},
+		Data:      data,
+	}
+}
+
+func decodeSystem(ix Instruction) (DecodedInstruction, error) {
+	d := &decoder{b: ix.Data}
+	kind, err := d.uint32()
+	if err != nil {
+		return nil, invalidData("system", err)
+	}
+
+	a := &accountsReader{ix: ix}
+	var decoded DecodedInstruction
+	switch kind {
+	case systemCreateAccount:
+		i := &SystemCreateAccount{From: a.next(), NewAccount: a.next()}
+		if i.Lamports, err = d.uint64(); err == nil {
+			if i.Space, err = d.uint64(); err == nil {
+				i.Owner, err = d.publicKey()
+			}
+		}
+		decoded = i
+	case systemAssign:
+		i := &SystemAssign{Account: a.next()}
+		i.Owner, err = d.publicKey()
+		decoded = i
+	case systemTransfer:
+		i := &SystemTransfer{From: a.next(), To: a.next()}
+		i.Lamports, err = d.uint64()
+		decoded = i
+	case systemAllocate:
+		i := &SystemAllocate{Account: a.next()}
+		i.Space, err = d.uint64()
+		decoded = i
+	default:
+		return nil, unknownInstruction("system", kind)
+	}
+	if err == nil {
+		err = a.err
+	}
+	if err != nil {
+		return nil, invalidData(decoded.Name(), err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,201 @@
+package solana
+
+import "encoding/binary"
+
+// SPL Token program instruction discriminators, shared by Token-2022.
+const (
+	tokenTransfer           = 3
+	tokenApprove            = 4
+	tokenCloseAccount       = 9
+	tokenTransferChecked    = 12
+	tokenSyncNative         = 17
+	tokenInitializeAccount3 = 18
+)
+
+// TokenTransfer transfers tokens between token accounts.
+type TokenTransfer struct {
+	Program     PublicKey // TokenProgramID or Token2022ProgramID
+	Source      PublicKey
+	Destination PublicKey
+	Owner       PublicKey
+	Amount      uint64
+}
+
+// TokenApprove approves a delegate to transfer tokens from a token account.
+type TokenApprove struct {
+	Program  PublicKey // TokenProgramID or Token2022ProgramID
+	Source   PublicKey
+	Delegate PublicKey
+	Owner    PublicKey
+	Amount   uint64
+}
+
+// TokenCloseAccount closes a token account, sending its lamports to Destination.
+type TokenCloseAccount struct {
+	Program     PublicKey // TokenProgramID or Token2022ProgramID
+	Account     PublicKey
+	Destination PublicKey
+	Owner       PublicKey
+}
+
+// TokenTransferChecked transfers tokens between token accounts, checking the mint and decimals.
+type TokenTransferChecked struct {
+	Program     PublicKey // TokenProgramID or Token2022ProgramID
+	Source      PublicKey
+	Mint        PublicKey
+	Destination PublicKey
+	Owner       PublicKey
+	Amount      uint64
+	Decimals    uint8
+}
+
+// TokenSyncNative syncs the token amount of a wrapped SOL account with its lamports.
+type TokenSyncNative struct {
+	Program PublicKey // TokenProgramID or Token2022ProgramID
+	Account PublicKey
+}
+
+// TokenInitializeAccount3 initializes a token account.
+type TokenInitializeAccount3 struct {
+	Program PublicKey // TokenProgramID or Token2022ProgramID
+	Account PublicKey
+	Mint    PublicKey
+	Owner   PublicKey
+}
+
+// ProgramID implements DecodedInstruction.
+func (i *TokenTransfer) ProgramID() PublicKey { return i.Program }
+
+// Name implements DecodedInstruction.
+func (*TokenTransfer) Name() string { return "Transfer" }
+
+// ProgramID implements DecodedInstruction.
+func (i *TokenApprove) ProgramID() PublicKey { return i.Program }
+
+// Name implements DecodedInstruction.
+func (*TokenApprove) Name() string { return "Approve" }
+
+// ProgramID implements DecodedInstruction.
+func (i *TokenCloseAccount) ProgramID() PublicKey { return i.Program }
+
+// Name implements DecodedInstruction.
+func (*TokenCloseAccount) Name() string { return "CloseAccount" }
+
+// ProgramID implements DecodedInstruction.
+func (i *TokenTransferChecked) ProgramID() PublicKey { return i.Program }
+
+// Name implements DecodedInstruction.
+func (*TokenTransferChecked) Name() string { return "TransferChecked" }
+
+// ProgramID implements DecodedInstruction.
+func (i *TokenSyncNative) ProgramID() PublicKey { return i.Program }
+
+// Name implements DecodedInstruction.
+func (*TokenSyncNative) Name() string { return "SyncNative" }
+
+// ProgramID implements DecodedInstruction.
+func (i *TokenInitializeAccount3) ProgramID() PublicKey { return i.Program }
+
+// Name implements DecodedInstruction.
+func (*TokenInitializeAccount3) Name() string { return "InitializeAccount3" }
+
+// tokenProgram returns program, or TokenProgramID if it is not set.
+func tokenProgram(program PublicKey) PublicKey {
+	if program.IsZero() {
+		return TokenProgramID
+	}
+	return program
+}
+
+// Instruction returns the instruction. The SPL Token program is used unless Program is set.
+func (i *TokenTransfer) Instruction() Instruction {
+	data := make([]byte, 9)
+	data[0] = tokenTransfer
+	binary.LittleEndian.PutUint64(data[1:], i.Amount)
+	return Instruction{
+		ProgramID: tokenProgram(i.Program),
+		Accounts:  []AccountMeta{Meta(i.Source, false, true), Meta(i.Destination, false, true), Meta(i.Owner, true, false)},
+		Data:      data,
+	}
+}
+
+// Instruction returns the instruction. The SPL Token program is used unless Program is set.
+func (i *TokenTransferChecked) Instruction() Instruction {
+	data := make([]byte, 10)
+	data[0] = tokenTransferChecked
+	binary.LittleEndian.PutUint64(data[1:], i.Amount)
+	data[9] = i.Decimals
+	return Instruction{
+		ProgramID: tokenProgram(i.Program),
+		Accounts: []AccountMeta{
+			Meta(i.Source, false, true),
+			Meta(i.Mint, false, false),
+			Meta(i.Destination, false, true),
+			Meta(i.Owner, true, false),
+		},
+		Data: data,
+	}
+}
+
+// Instruction returns the instruction. The SPL Token program is used unless Program is set.
+func (i *TokenSyncNative) Instruction() Instruction {
+	return Instruction{
+		ProgramID: tokenProgram(i.Program),
+		Accounts:  []AccountMeta{Meta(i.Account, false, true)},
+		Data:      []byte{tokenSyncNative},
+	}
+}
+
+// Instruction returns the instruction. The SPL Token program is used unless Program is set.
+func (i *TokenCloseAccount) Instruction() Instruction {
+	return Instruction{
+		ProgramID: tokenProgram(i.Program),
+		Accounts:  []AccountMeta{Meta(i.Account, false, true), Meta(i.Destination, false, true), Meta(i.Owner, true, false)},
+		Data:      []byte{tokenCloseAccount},
+	}
+}
+
+func decodeToken(ix Instruction) (DecodedInstruction, error) {
+	d := &decoder{b: ix.Data}
+	kind, err := d.byte()
+	if err != nil {
+		return nil, invalidData("token", err)
+	}
+
+	a := &accountsReader{ix: ix}
+	var decoded DecodedInstruction
+	switch kind {
+	case tokenTransfer:
+		i := &TokenTransfer{Program: ix.ProgramID, Source: a.next(), Destination: a.next(), Owner: a.next()}
+		i.Amount, err = d.uint64()
+		decoded = i
+	case tokenApprove:
+		i := &TokenApprove{Program: ix.ProgramID, Source: a.next(), Delegate: a.next(), Owner: a.next()}
+		i.Amount, err = d.uint64()
+		decoded = i
+	case tokenCloseAccount:
+		decoded = &TokenCloseAccount{Program: ix.ProgramID, Account: a.next(), Destination: a.next(), Owner: a.next()}
+	case tokenTransferChecked:
+		i := &TokenTransferChecked{Program: ix.ProgramID, Source: a.next(), Mint: a.next(), Destination: a.next(), Owner: a.next()}
+		if i.Amount, err = d.uint64(); err == nil {
+			i.Decimals, err = d.byte()
+		}
+		decoded = i
+	case tokenSyncNative:
+		decoded = &TokenSyncNative{Program: ix.ProgramID, Account: a.next()}
+	case tokenInitializeAccount3:
+		i := &TokenInitializeAccount3{Program: ix.ProgramID, Account: a.next(), Mint: a.next()}
+		i.Owner, err = d.publicKey()
+		decoded = i
+	default:
+		return nil, unknownInstruction("token", kind)
+	}
+	if err == nil {
+		err = a.err
+	}
+	if err != nil {
+		return nil, invalidData(decoded.Name(), err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,455 @@
+package solana
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+)
+
+// SignatureLength is the length of an ed25519 signature in bytes.
+const SignatureLength = 64
+
+// Signature is an ed25519 transaction signature. It is encoded in JSON and text as base58.
+type Signature [SignatureLength]byte
+
+// SignatureFromBase58 parses a base58 encoded signature.
+func SignatureFromBase58(s string) (Signature, error) {
+	b, err := DecodeBase58(s)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid signature %q: %w", s, err)
+	}
+	if len(b) != SignatureLength {
+		return Signature{}, fmt.Errorf("invalid signature %q: decoded length is %d, want %d", s, len(b), SignatureLength)
+	}
+
+	var sig Signature
+	copy(sig[:], b)
+
+	return sig, nil
+}
+
+// String returns the base58 encoding of the signature.
+func (s Signature) String() string {
+	return EncodeBase58(s[:])
+}
+
+// IsZero reports whether the signature is all zeros, which is the case for a transaction that was not signed yet.
+func (s Signature) IsZero() bool {
+	return s == Signature{}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Signature) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Signature) UnmarshalText(text []byte) error {
+	parsed, err := SignatureFromBase58(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// MessageVersion is the version of a transaction message.
+type MessageVersion int
+
+// Supported message versions.
+const (
+	MessageVersionLegacy MessageVersion = -1
+	MessageVersionV0     MessageVersion = 0
+)
+
+// String implements fmt.Stringer.
+func (v MessageVersion) String() string {
+	if v == MessageVersionLegacy {
+		return "legacy"
+	}
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// versionPrefix flags the first byte of a versioned message.
+const versionPrefix = 0x80
+
+// MessageHeader describes which account keys of a message are signers and which are read-only.
+type MessageHeader struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+}
+
+// CompiledInstruction is an instruction whose program and accounts are indexes into the message account keys.
+type CompiledInstruction struct {
+	ProgramIDIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// MessageAddressTableLookup loads accounts of a v0 message from an address lookup table.
+// Loaded accounts are indexed after the static account keys, writable ones first.
+type MessageAddressTableLookup struct {
+	AccountKey      PublicKey // address of the lookup table
+	WritableIndexes []uint8
+	ReadonlyIndexes []uint8
+}
+
+// Message is the signed part of a transaction.
+type Message struct {
+	Version             MessageVersion
+	Header              MessageHeader
+	AccountKeys         []PublicKey // static account keys, signers first
+	RecentBlockhash     Hash
+	Instructions        []CompiledInstruction
+	AddressTableLookups []MessageAddressTableLookup // v0 only
+}
+
+// Transaction is a legacy or versioned Solana transaction.
+type Transaction struct {
+	Signatures []Signature // one per required signature, in the order of the signer account keys
+	Message    Message
+}
+
+// TransactionFromBytes decodes a serialized legacy or v0 transaction.
+func TransactionFromBytes(b []byte) (*Transaction, error) {
+	var tx Transaction
+	if err := tx.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// TransactionFromBase64 decodes a base64 encoded transaction, as returned by the Jupiter swap API.
+func TransactionFromBase64(s string) (*Transaction, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 transaction: %w", err)
+	}
+	return TransactionFromBytes(b)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (tx *Transaction) UnmarshalBinary(b []byte) error {
+	d := &decoder{b: b}
+
+	n, err := d.vecLen(SignatureLength)
+	if err != nil {
+		return fmt.Errorf("failed to decode signatures: %w", err)
+	}
+	sigs := make([]Signature, n)
+	for i := range sigs {
+		raw, err := d.bytes(SignatureLength)
+		if err != nil {
+			return fmt.Errorf("failed to decode signature %d: %w", i, err)
+		}
+		copy(sigs[i][:], raw)
+	}
+
+	var msg Message
+	if err := msg.decode(d); err != nil {
+		return err
+	}
+	if d.pos != len(b) {
+		return fmt.Errorf("unexpected %d trailing bytes", len(b)-d.pos)
+	}
+	if len(sigs) != int(msg.Header.NumRequiredSignatures) {
+		return fmt.Errorf("transaction has %d signatures, message requires %d", len(sigs), msg.Header.NumRequiredSignatures)
+	}
+
+	tx.Signatures = sigs
+	tx.Message = msg
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := appendShortVecLen(nil, len(tx.Signatures))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signatures: %w", err)
+	}
+	for _, sig := range tx.Signatures {
+		b = append(b, sig[:]...)
+	}
+
+	return append(b, msg...), nil
+}
+
+// Base64 returns the base64 encoding of the serialized transaction.
+func (tx *Transaction) Base64() (string, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// MessageFromBytes decodes a serialized legacy or v0 message.
+func MessageFromBytes(b []byte) (*Message, error) {
+	d := &decoder{b: b}
+
+	var msg Message
+	if err := msg.decode(d); err != nil {
+		return nil, err
+	}
+	if d.pos != len(b) {
+		return nil, fmt.Errorf("unexpected %d trailing bytes", len(b)-d.pos)
+	}
+
+	return &msg, nil
+}
+
+func (m *Message) decode(d *decoder) error {
+	first, err := d.byte()
+	if err != nil {
+		return fmt.Errorf("failed to decode message header: %w", err)
+	}
+
+	m.Version = MessageVersionLegacy
+	if first&versionPrefix != 0 {
+		m.Version = MessageVersion(first &^ versionPrefix)
+		if m.Version != MessageVersionV0 {
+			return fmt.Errorf("unsupported message version %s", m.Version)
+		}
+		if first, err = d.byte(); err != nil {
+			return fmt.Errorf("failed to decode message header: %w", err)
+		}
+	}
+
+	header, err := d.bytes(2)
+	if err != nil {
+		return fmt.Errorf("failed to decode message header: %w", err)
+	}
+	m.Header = MessageHeader{
+		NumRequiredSignatures:       first,
+		NumReadonlySignedAccounts:   header[0],
+		NumReadonlyUnsignedAccounts: header[1],
+	}
+
+	n, err := d.vecLen(PublicKeyLength)
+	if err != nil {
+		return fmt.Errorf("failed to decode account keys: %w", err)
+	}
+	m.AccountKeys = make([]PublicKey, n)
+	for i := range m.AccountKeys {
+		if m.AccountKeys[i], err = d.publicKey(); err != nil {
+			return fmt.Errorf("failed to decode account key %d: %w", i, err)
+		}
+	}
+
+	raw, err := d.bytes(HashLength)
+	if err != nil {
+		return fmt.Errorf("failed to decode recent blockhash: %w", err)
+	}
+	copy(m.RecentBlockhash[:], raw)
+
+	// An instruction is at least its program index and two empty arrays.
+	if n, err = d.vecLen(3); err != nil {
+		return fmt.Errorf("failed to decode instructions: %w", err)
+	}
+	m.Instructions = make([]CompiledInstruction, n)
+	for i := range m.Instructions {
+		ix := &m.Instructions[i]
+		if ix.ProgramIDIndex, err = d.byte(); err != nil {
+			return fmt.Errorf("failed to decode instruction %d: %w", i, err)
+		}
+		if ix.Accounts, err = d.byteVec(); err != nil {
+			return fmt.Errorf("failed to decode instruction %d accounts: %w", i, err)
+		}
+		if ix.Data, err = d.byteVec(); err != nil {
+			return fmt.Errorf("failed to decode instruction %d data: %w", i, err)
+		}
+	}
+
+	if m.Version == MessageVersionLegacy {
+		return nil
+	}
+
+	if n, err = d.vecLen(PublicKeyLength + 2); err != nil {
+		return fmt.Errorf("failed to decode address table lookups: %w", err)
+	}
+	m.AddressTableLookups = make([]MessageAddressTableLookup, n)
+	for i := range m.AddressTableLookups {
+		l := &m.AddressTableLookups[i]
+		if l.AccountKey, err = d.publicKey(); err != nil {
+			return fmt.Errorf("failed to decode address table lookup %d: %w", i, err)
+		}
+		if l.WritableIndexes, err = d.byteVec(); err != nil {
+			return fmt.Errorf("failed to decode address table lookup %d: %w", i, err)
+		}
+		if l.ReadonlyIndexes, err = d.byteVec(); err != nil {
+			return fmt.Errorf("failed to decode address table lookup %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The result is the data signed by the transaction signers.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	var b []byte
+
+	switch m.Version {
+	case MessageVersionLegacy:
+		if len(m.AddressTableLookups) > 0 {
+			return nil, fmt.Errorf("legacy message cannot have address table lookups")
+		}
+	case MessageVersionV0:
+		b = append(b, versionPrefix|byte(m.Version))
+	default:
+		return nil, fmt.Errorf("unsupported message version %s", m.Version)
+	}
+
+	b = append(b, m.Header.NumRequiredSignatures, m.Header.NumReadonlySignedAccounts, m.Header.NumReadonlyUnsignedAccounts)
+
+	b, err := appendShortVecLen(b, len(m.AccountKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode account keys: %w", err)
+	}
+	for _, k := range m.AccountKeys {
+		b = append(b, k[:]...)
+	}
+
+	b = append(b, m.RecentBlockhash[:]...)
+
+	if b, err = appendShortVecLen(b, len(m.Instructions)); err != nil {
+		return nil, fmt.Errorf("failed to encode instructions: %w", err)
+	}
+	for i, ix := range m.Instructions {
+		b = append(b, ix.ProgramIDIndex)
+		if b, err = appendShortVec(b, ix.Accounts); err != nil {
+			return nil, fmt.Errorf("failed to encode instruction %d accounts: %w", i, err)
+		}
+		if b, err = appendShortVec(b, ix.Data); err != nil {
+			return nil, fmt.Errorf("failed to encode instruction %d data: %w", i, err)
+		}
+	}
+
+	if m.Version == MessageVersionLegacy {
+		return b, nil
+	}
+
+	if b, err = appendShortVecLen(b, len(m.AddressTableLookups)); err != nil {
+		return nil, fmt.Errorf("failed to encode address table lookups: %w", err)
+	}
+	for i, l := range m.AddressTableLookups {
+		b = append(b, l.AccountKey[:]...)
+		if b, err = appendShortVec(b, l.WritableIndexes); err != nil {
+			return nil, fmt.Errorf("failed to encode address table lookup %d: %w", i, err)
+		}
+		if b, err = appendShortVec(b, l.ReadonlyIndexes); err != nil {
+			return nil, fmt.Errorf("failed to encode address table lookup %d: %w", i, err)
+		}
+	}
+
+	return b, nil
+}
+
+// Signers returns the account keys that must sign the message, in signature order.
+func (m *Message) Signers() []PublicKey {
+	n := int(m.Header.NumRequiredSignatures)
+	if n > len(m.AccountKeys) {
+		n = len(m.AccountKeys)
+	}
+	return append([]PublicKey(nil), m.AccountKeys[:n]...)
+}
+
+// IsSigner reports whether the account at the given index must sign the message.
+func (m *Message) IsSigner(index int) bool {
+	return index >= 0 && index < int(m.Header.NumRequiredSignatures)
+}
+
+// IsWritable reports whether the account at the given index is writable.
+// Indexes past the static account keys refer to accounts loaded from address lookup tables.
+func (m *Message) IsWritable(index int) bool {
+	numKeys := len(m.AccountKeys)
+	switch {
+	case index < 0:
+		return false
+	case index < int(m.Header.NumRequiredSignatures):
+		return index < int(m.Header.NumRequiredSignatures)-int(m.Header.NumReadonlySignedAccounts)
+	case index < numKeys:
+		return index < numKeys-int(m.Header.NumReadonlyUnsignedAccounts)
+	}
+
+	index -= numKeys
+	for _, l := range m.AddressTableLookups {
+		if index < len(l.WritableIndexes) {
+			return true
+		}
+		index -= len(l.WritableIndexes)
+	}
+
+	return false
+}
+
+// Sign signs the message with the given signers and places each signature in the slot of its signer.
+// Signatures of other signers are left untouched, so a transaction can be signed in several steps.
+func (tx *Transaction) Sign(ctx context.Context, signers ...Signer) error {
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	required := tx.Message.Signers()
+	if len(tx.Signatures) != len(required) {
+		sigs := make([]Signature, len(required))
+		copy(sigs, tx.Signatures)
+		tx.Signatures = sigs
+	}
+
+	for _, signer := range signers {
+		key := signer.PublicKey()
+
+		idx := -1
+		for i, k := range required {
+			if k == key {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return fmt.Errorf("%s is not a signer of the transaction", key)
+		}
+
+		sig, err := signer.Sign(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("failed to sign for %s: %w", key, err)
+		}
+		if !key.Verify(msg, sig) {
+			return fmt.Errorf("invalid signature for %s", key)
+		}
+		tx.Signatures[idx] = sig
+	}
+
+	return nil
+}
+
+// Verify checks that every required signature is present and valid.
+func (tx *Transaction) Verify() error {
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	required := tx.Message.Signers()
+	if len(tx.Signatures) != len(required) {
+		return fmt.Errorf("transaction has %d signatures, message requires %d", len(tx.Signatures), len(required))
+	}
+	for i, key := range required {
+		if tx.Signatures[i].IsZero() {
+			return fmt.Errorf("missing signature for %s", key)
+		}
+		if !key.Verify(msg, tx.Signatures[i]) {
+			return fmt.Errorf("invalid signature for %s", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package solana_test
+
+import (
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	payer     = solana.MustPublicKeyFromBase58("8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W")
+	blockhash = solana.MustHashFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N")
+	table     = solana.MustPublicKeyFromBase58("2immgwYNHBbyVQKVGCEkgWpi53bLwWNRMB5G2nbgYV17")
+)
+
+func testTransaction(version solana.MessageVersion) *solana.Transaction {
+	tx := &solana.Transaction{
+		Signatures: make([]solana.Signature, 1),
+		Message: solana.Message{
+			Version: version,
+			Header:  solana.MessageHeader{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 1},
+			AccountKeys: []solana.PublicKey{
+				payer,
+				solana.USDCMint,
+				solana.ComputeBudgetProgramID,
+			},
+			RecentBlockhash: blockhash,
+			Instructions: []solana.CompiledInstruction{
+				{ProgramIDIndex: 2, Data: []byte{2, 0xc0, 0x5c, 0x15, 0}},
+				{ProgramIDIndex: 2, Accounts: []uint8{0, 1}, Data: make([]byte, 200)},
+			},
+		},
+	}
+	if version == solana.MessageVersionV0 {
+		tx.Message.AddressTableLookups = []solana.MessageAddressTableLookup{
+			{AccountKey: table, WritableIndexes: []uint8{3}, ReadonlyIndexes: []uint8{1, 2}},
+		}
+	}
+	return tx
+}
+
+func TestTransactionRoundTrip(t *testing.T) {
+	for _, version := range []solana.MessageVersion{solana.MessageVersionLegacy, solana.MessageVersionV0} {
+		t.Run(version.String(), func(t *testing.T) {
+			tx := testTransaction(version)
+
+			b, err := tx.MarshalBinary()
+			require.NoError(t, err)
+
+			decoded, err := solana.TransactionFromBytes(b)
+			require.NoError(t, err)
+			assert.Equal(t, tx, decoded)
+
+			again, err := decoded.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, b, again)
+
+			s, err := tx.Base64()
+			require.NoError(t, err)
+			fromBase64, err := solana.TransactionFromBase64(s)
+			require.NoError(t, err)
+			assert.Equal(t, tx, fromBase64)
+
+			msg, err := tx.Message.MarshalBinary()
+			require.NoError(t, err)
+			decodedMsg, err := solana.MessageFromBytes(msg)
+			require.NoError(t, err)
+			assert.Equal(t, &tx.Message, decodedMsg)
+		})
+	}
+}
+
+func TestTransactionEncoding(t *testing.T) {
+	b, err := testTransaction(solana.MessageVersionV0).MarshalBinary()
+	require.NoError(t, err)
+
+	// One zero signature, then the v0 prefix and the header.
+	assert.Equal(t, byte(1), b[0])
+	assert.Equal(t, []byte{0x80, 1, 0, 1, 3}, b[65:70])
+	// The 200 byte instruction data length is encoded as a two byte compact-u16.
+	assert.Contains(t, string(b), string([]byte{2, 0, 1, 0xc8, 0x01}))
+
+	legacy, err := testTransaction(solana.MessageVersionLegacy).MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 0, 1, 3}, legacy[65:69])
+}
+
+func TestTransactionDecodeErrors(t *testing.T) {
+	b, err := testTransaction(solana.MessageVersionV0).MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run("truncated", func(t *testing.T) {
+		for _, n := range []int{0, 1, 64, 66, 100, len(b) - 1} {
+			_, err := solana.TransactionFromBytes(b[:n])
+			assert.Error(t, err, n)
+		}
+	})
+
+	t.Run("trailing bytes", func(t *testing.T) {
+		_, err := solana.TransactionFromBytes(append(append([]byte(nil), b...), 0))
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		bad := append([]byte(nil), b...)
+		bad[65] = 0x81
+		_, err := solana.TransactionFromBytes(bad)
+		assert.EqualError(t, err, "unsupported message version v1")
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		bad := append([]byte{0}, b[65:]...)
+		_, err := solana.TransactionFromBytes(bad)
+		assert.Error(t, err)
+	})
+
+	t.Run("length exceeds data", func(t *testing.T) {
+		// 0xffff signatures are announced by a three byte compact-u16 but not present.
+		_, err := solana.TransactionFromBytes([]byte{0xff, 0xff, 0x03, 0})
+		assert.EqualError(t, err, "failed to decode signatures: unexpected end of data")
+
+		bad := append([]byte(nil), b[:69]...)
+		bad = append(bad, 0xff, 0xff, 0x03)
+		_, err = solana.TransactionFromBytes(bad)
+		assert.EqualError(t, err, "failed to decode account keys: unexpected end of data")
+	})
+
+	t.Run("invalid base64", func(t *testing.T) {
+		_, err := solana.TransactionFromBase64("not base64!")
+		assert.Error(t, err)
+	})
+
+	t.Run("legacy with lookups", func(t *testing.T) {
+		tx := testTransaction(solana.MessageVersionV0)
+		tx.Message.Version = solana.MessageVersionLegacy
+		_, err := tx.MarshalBinary()
+		assert.Error(t, err)
+	})
+
+	t.Run("length overflow", func(t *testing.T) {
+		tx := testTransaction(solana.MessageVersionV0)
+		tx.Message.Instructions[1].Data = make([]byte, 70000)
+
+		var err error
+		require.NotPanics(t, func() { _, err = tx.MarshalBinary() })
+		assert.EqualError(t, err, "failed to encode instruction 1 data: length 70000 does not fit in a compact-u16")
+
+		_, err = tx.Base64()
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageAccounts(t *testing.T) {
+	msg := testTransaction(solana.MessageVersionV0).Message
+	msg.Header = solana.MessageHeader{NumRequiredSignatures: 2, NumReadonlySignedAccounts: 1, NumReadonlyUnsignedAccounts: 1}
+	msg.AccountKeys = append(msg.AccountKeys, solana.SystemProgramID)
+
+	assert.Equal(t, []solana.PublicKey{payer, solana.USDCMint}, msg.Signers())
+
+	signer := []bool{true, true, false, false, false, false, false}
+	writable := []bool{true, false, true, false, true, false, false}
+	for i := range signer {
+		assert.Equal(t, signer[i], msg.IsSigner(i), "signer %d", i)
+		assert.Equal(t, writable[i], msg.IsWritable(i), "writable %d", i)
+	}
+}
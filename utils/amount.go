@@ -3,17 +3,21 @@ package utils
 import (
 	"fmt"
 	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
 // AmountToFloat64 converts amount lamports to float64 with given decimals.
+// It loses precision above 2^53 base units, use Amount for exact conversions.
 func AmountToFloat64(amount uint64, decimals uint8) float64 {
 	return float64(amount) / math.Pow10(int(decimals))
 }
 
-// AmountToUint64 converts amount from float64 to uint64 with given decimals.
+// AmountToUint64 converts amount from float64 to uint64 with given decimals, rounding to the nearest base unit.
+// It loses precision above 2^53 base units, use ParseDecimalAmount for exact conversions.
 func AmountToUint64(amount float64, decimals uint8) uint64 {
-	return uint64(amount * math.Pow10(int(decimals)))
+	return uint64(math.Round(amount * math.Pow10(int(decimals))))
 }
 
 // AmountToString converts amount lamports to string with given decimals.
@@ -41,3 +45,205 @@ func Float64ToString(amount float64) string {
 
 	return s
 }
+
+// Amount is an arbitrary-precision token amount in base units (e.g. lamports).
+// The zero value is an amount of 0. Amounts are immutable: arithmetic methods return new values.
+//
+// Amount is encoded in JSON as a string of base units, the way the Jupiter API encodes amounts,
+// and decodes from either a JSON string or number.
+type Amount struct {
+	i *big.Int
+}
+
+// NewAmount returns an amount of v base units.
+func NewAmount(v uint64) Amount {
+	return Amount{i: new(big.Int).SetUint64(v)}
+}
+
+// NewAmountFromBig returns an amount of v base units. The value is copied.
+func NewAmountFromBig(v *big.Int) Amount {
+	if v == nil {
+		return Amount{}
+	}
+	return Amount{i: new(big.Int).Set(v)}
+}
+
+// ParseAmount parses an integer string of base units, e.g. "100000".
+func ParseAmount(s string) (Amount, error) {
+	if !isDigits(strings.TrimPrefix(s, "-")) {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	return Amount{i: i}, nil
+}
+
+// ParseDecimalAmount parses a decimal string in whole tokens with the given decimals, e.g. "0.29" with 2 decimals is 29 base units.
+// Parsing is exact: it fails if the string has more significant fractional digits than decimals.
+func ParseDecimalAmount(s string, decimals uint8) (Amount, error) {
+	neg := strings.HasPrefix(s, "-")
+	intPart, fracPart, hasDot := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if intPart == "" && fracPart == "" || intPart != "" && !isDigits(intPart) || hasDot && fracPart != "" && !isDigits(fracPart) {
+		return Amount{}, fmt.Errorf("invalid decimal amount %q", s)
+	}
+
+	trimmed := strings.TrimRight(fracPart, "0")
+	if len(trimmed) > int(decimals) {
+		return Amount{}, fmt.Errorf("decimal amount %q has more than %d decimals", s, decimals)
+	}
+
+	digits := intPart + trimmed + strings.Repeat("0", int(decimals)-len(trimmed))
+	i, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid decimal amount %q", s)
+	}
+	if neg {
+		i.Neg(i)
+	}
+
+	return Amount{i: i}, nil
+}
+
+// MustParseAmount is like ParseAmount but panics if the string cannot be parsed.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// big returns the underlying value, never nil. It must not be modified.
+func (a Amount) big() *big.Int {
+	if a.i == nil {
+		return new(big.Int)
+	}
+	return a.i
+}
+
+// Big returns a copy of the amount as a big.Int.
+func (a Amount) Big() *big.Int {
+	return new(big.Int).Set(a.big())
+}
+
+// Uint64 returns the amount as uint64 and whether it fits.
+func (a Amount) Uint64() (uint64, bool) {
+	i := a.big()
+	if !i.IsUint64() {
+		return 0, false
+	}
+	return i.Uint64(), true
+}
+
+// String returns the amount in base units.
+func (a Amount) String() string {
+	return a.big().String()
+}
+
+// FormatDecimals returns the amount in whole tokens with the given decimals and minimum number of fractional digits.
+// For example, 1500000000 with 9 decimals is "1.5" and 1000000000 is "1".
+func (a Amount) FormatDecimals(decimals uint8) string {
+	i := a.big()
+	digits := new(big.Int).Abs(i).String()
+
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	intPart, fracPart := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if i.Sign() < 0 {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// Add returns a + b.
+func (a Amount) Add(b Amount) Amount {
+	return Amount{i: new(big.Int).Add(a.big(), b.big())}
+}
+
+// Sub returns a - b.
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{i: new(big.Int).Sub(a.big(), b.big())}
+}
+
+// Mul returns a * b.
+func (a Amount) Mul(b Amount) Amount {
+	return Amount{i: new(big.Int).Mul(a.big(), b.big())}
+}
+
+// Quo returns a / b truncated towards zero. It panics if b is zero.
+func (a Amount) Quo(b Amount) Amount {
+	return Amount{i: new(big.Int).Quo(a.big(), b.big())}
+}
+
+// Cmp compares a and b and returns -1 if a < b, 0 if a == b and +1 if a > b.
+func (a Amount) Cmp(b Amount) int {
+	return a.big().Cmp(b.big())
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	return a.big().Sign()
+}
+
+// IsZero reports whether the amount is 0.
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *Amount) UnmarshalText(text []byte) error {
+	parsed, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	return a.UnmarshalText([]byte(s))
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
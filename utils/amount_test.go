@@ -1,9 +1,13 @@
 package utils_test
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/qiruos/jupiter/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAmountToFloat64(t *testing.T) {
@@ -100,6 +104,14 @@ func TestAmountToUint64(t *testing.T) {
 			},
 			want: 99999999999999,
 		},
+		{
+			name: "0.29 with decimals 2",
+			args: args{
+				amount:   0.29,
+				decimals: 2,
+			},
+			want: 29,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -161,3 +173,136 @@ func TestAmountToString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDecimalAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		decimals uint8
+		want     string
+		wantErr  bool
+	}{
+		{name: "integer", s: "1", decimals: 9, want: "1000000000"},
+		{name: "fraction", s: "0.29", decimals: 2, want: "29"},
+		{name: "leading dot", s: ".5", decimals: 1, want: "5"},
+		{name: "trailing dot", s: "5.", decimals: 1, want: "50"},
+		{name: "trailing zeros beyond decimals", s: "1.500", decimals: 1, want: "15"},
+		{name: "zero decimals", s: "42", decimals: 0, want: "42"},
+		{name: "negative", s: "-1.5", decimals: 9, want: "-1500000000"},
+		{name: "above 2^53", s: "123456789.123456789", decimals: 9, want: "123456789123456789"},
+		{name: "above 2^64", s: "184467440737.09551616", decimals: 8, want: "18446744073709551616"},
+		{name: "too many decimals", s: "0.123", decimals: 2, wantErr: true},
+		{name: "empty", s: "", decimals: 2, wantErr: true},
+		{name: "dot only", s: ".", decimals: 2, wantErr: true},
+		{name: "exponent", s: "1e9", decimals: 2, wantErr: true},
+		{name: "two dots", s: "1.2.3", decimals: 2, wantErr: true},
+		{name: "plus sign", s: "+1", decimals: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.ParseDecimalAmount(tt.s, tt.decimals)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestAmountFormatDecimals(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   utils.Amount
+		decimals uint8
+		want     string
+	}{
+		{name: "zero value", amount: utils.Amount{}, decimals: 9, want: "0"},
+		{name: "whole", amount: utils.NewAmount(1000000000), decimals: 9, want: "1"},
+		{name: "fraction", amount: utils.NewAmount(1500000000), decimals: 9, want: "1.5"},
+		{name: "below one", amount: utils.NewAmount(29), decimals: 2, want: "0.29"},
+		{name: "smallest unit", amount: utils.NewAmount(1), decimals: 9, want: "0.000000001"},
+		{name: "no decimals", amount: utils.NewAmount(42), decimals: 0, want: "42"},
+		{name: "negative", amount: utils.NewAmount(0).Sub(utils.NewAmount(15)), decimals: 1, want: "-1.5"},
+		{name: "max uint64", amount: utils.NewAmount(18446744073709551615), decimals: 9, want: "18446744073.709551615"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.amount.FormatDecimals(tt.decimals))
+
+			parsed, err := utils.ParseDecimalAmount(tt.want, tt.decimals)
+			require.NoError(t, err)
+			assert.Equal(t, 0, parsed.Cmp(tt.amount))
+		})
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := utils.NewAmount(18446744073709551615)
+	b := utils.NewAmount(1)
+
+	sum := a.Add(b)
+	assert.Equal(t, "18446744073709551616", sum.String())
+	_, ok := sum.Uint64()
+	assert.False(t, ok)
+
+	assert.Equal(t, 0, sum.Sub(b).Cmp(a))
+	assert.Equal(t, "36893488147419103230", a.Mul(utils.NewAmount(2)).String())
+	assert.Equal(t, "6148914691236517205", a.Quo(utils.NewAmount(3)).String())
+	assert.Equal(t, -1, b.Cmp(a))
+	assert.Equal(t, 1, a.Cmp(b))
+	assert.True(t, utils.Amount{}.IsZero())
+	assert.Equal(t, -1, b.Sub(a).Sign())
+
+	// Operands are not modified.
+	assert.Equal(t, "18446744073709551615", a.String())
+	assert.Equal(t, "1", b.String())
+
+	v, ok := a.Uint64()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(18446744073709551615), v)
+
+	copied := a.Big()
+	copied.SetInt64(0)
+	assert.Equal(t, "18446744073709551615", a.String())
+}
+
+func TestAmountJSON(t *testing.T) {
+	t.Run("marshal", func(t *testing.T) {
+		buf, err := json.Marshal(map[string]utils.Amount{"amount": utils.NewAmountFromBig(new(big.Int).Lsh(big.NewInt(1), 70))})
+		require.NoError(t, err)
+		assert.Equal(t, `{"amount":"1180591620717411303424"}`, string(buf))
+	})
+
+	t.Run("unmarshal quote amounts", func(t *testing.T) {
+		var quote struct {
+			InAmount             utils.Amount `json:"inAmount"`
+			OutAmount            utils.Amount `json:"outAmount"`
+			OtherAmountThreshold utils.Amount `json:"otherAmountThreshold"`
+			PlatformFee          *struct {
+				Amount utils.Amount `json:"amount"`
+			} `json:"platformFee"`
+		}
+		err := json.Unmarshal([]byte(`{"inAmount":"100000","outAmount":"18446744073709551616","otherAmountThreshold":14877,"platformFee":{"amount":"30","feeBps":20}}`), &quote)
+		require.NoError(t, err)
+		assert.Equal(t, "100000", quote.InAmount.String())
+		assert.Equal(t, "18446744073709551616", quote.OutAmount.String())
+		assert.Equal(t, "14877", quote.OtherAmountThreshold.String())
+		assert.Equal(t, "30", quote.PlatformFee.Amount.String())
+	})
+
+	t.Run("unmarshal invalid", func(t *testing.T) {
+		var a utils.Amount
+		assert.Error(t, json.Unmarshal([]byte(`"1.5"`), &a))
+		assert.Error(t, json.Unmarshal([]byte(`"abc"`), &a))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var a utils.Amount
+		require.NoError(t, a.UnmarshalText([]byte("123")))
+		text, err := a.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, "123", string(text))
+	})
+}
@@ -0,0 +1,150 @@
+package v6
+
+import (
+	"fmt"
+
+	"github.com/qiruos/jupiter/solana"
+)
+
+// BuildTransactionOptions configures SwapInstructionsResp.BuildTransaction.
+type BuildTransactionOptions struct {
+	Payer           solana.PublicKey // required. Fee payer, the SwapParams.UserPublicKey the instructions were requested for
+	RecentBlockhash solana.Hash      // required. Recent blockhash of the transaction
+
+	// AddressLookupTables has the content of the tables of SwapInstructionsResp.AddressLookupTableAddresses,
+	// which must all be given. Additional tables can be given to compress the user instructions.
+	AddressLookupTables []solana.AddressLookupTable
+
+	BeforeSwap []solana.Instruction // instructions to run after the setup and token ledger instructions, right before the swap
+	AfterSwap  []solana.Instruction // instructions to run after the cleanup instruction
+}
+
+// SolanaInstruction converts the instruction to a solana.Instruction, decoding its base64 data.
+func (i Instruction) SolanaInstruction() (solana.Instruction, error) {
+	data, err := i.DataBytes()
+	if err != nil {
+		return solana.Instruction{}, err
+	}
+
+	ix := solana.Instruction{ProgramID: i.ProgramId, Data: data}
+	if len(i.Accounts) > 0 {
+		ix.Accounts = make([]solana.AccountMeta, len(i.Accounts))
+		for j, a := range i.Accounts {
+			ix.Accounts[j] = solana.Meta(a.Pubkey, a.IsSigner, a.IsWritable)
+		}
+	}
+
+	return ix, nil
+}
+
+// Instructions returns the instructions of the swap in execution order: compute budget, setup,
+// token ledger, before, swap, cleanup, after and other instructions.
+func (r *SwapInstructionsResp) Instructions(before, after []solana.Instruction) ([]solana.Instruction, error) {
+	var instructions []solana.Instruction
+	add := func(name string, ixs ...Instruction) error {
+		for _, ix := range ixs {
+			converted, err := ix.SolanaInstruction()
+			if err != nil {
+				return fmt.Errorf("failed to convert %s instruction: %w", name, err)
+			}
+			instructions = append(instructions, converted)
+		}
+		return nil
+	}
+
+	if err := add("compute budget", r.ComputeBudgetInstructions...); err != nil {
+		return nil, err
+	}
+	if err := add("setup", r.SetupInstructions...); err != nil {
+		return nil, err
+	}
+	if r.TokenLedgerInstruction != nil {
+		if err := add("token ledger", *r.TokenLedgerInstruction); err != nil {
+			return nil, err
+		}
+	}
+	instructions = append(instructions, before...)
+	if err := add("swap", r.SwapInstruction); err != nil {
+		return nil, err
+	}
+	if r.CleanupInstruction != nil {
+		if err := add("cleanup", *r.CleanupInstruction); err != nil {
+			return nil, err
+		}
+	}
+	instructions = append(instructions, after...)
+	if err := add("other", r.OtherInstructions...); err != nil {
+		return nil, err
+	}
+
+	return instructions, nil
+}
+
+// TokenLedgerInstructions returns the instructions of a token ledger swap, which swaps the amount the
+// transfers add to the input token account rather than the quoted input amount. The transfers run
+// between the token ledger instruction, recording the amount of the input token account, and the swap.
+// The swap instructions must have been requested with SwapParams.UseTokenLedger.
+func (r *SwapInstructionsResp) TokenLedgerInstructions(transfers ...solana.Instruction) ([]solana.Instruction, error) {
+	if r.TokenLedgerInstruction == nil {
+		return nil, fmt.Errorf("no token ledger instruction, the swap instructions must be requested with useTokenLedger")
+	}
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("at least one transfer instruction is required")
+	}
+
+	decoded, err := r.SwapInstruction.Decode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode swap instruction: %w", err)
+	}
+	if route, ok := decoded.(*solana.JupiterRoute); !ok || !route.TokenLedger {
+		return nil, fmt.Errorf("swap instruction %s does not use the token ledger", decoded.Name())
+	}
+
+	return r.Instructions(transfers, nil)
+}
+
+// BuildTransaction compiles the swap instructions and the user instructions into an unsigned v0 transaction.
+// Accounts found in the address lookup tables are loaded from them to keep the transaction under
+// solana.MaxTransactionSize.
+func (r *SwapInstructionsResp) BuildTransaction(opts BuildTransactionOptions) (*solana.Transaction, error) {
+	if opts.Payer.IsZero() {
+		return nil, fmt.Errorf("payer is required")
+	}
+	if opts.RecentBlockhash.IsZero() {
+		return nil, fmt.Errorf("recent blockhash is required")
+	}
+
+	for _, addr := range r.AddressLookupTableAddresses {
+		found := false
+		for _, table := range opts.AddressLookupTables {
+			if table.Key == addr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("address lookup table %s is not resolved", addr)
+		}
+	}
+
+	instructions, err := r.Instructions(opts.BeforeSwap, opts.AfterSwap)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := solana.NewMessageV0(opts.Payer, instructions, opts.RecentBlockhash, opts.AddressLookupTables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile message: %w", err)
+	}
+
+	tx := solana.NewTransaction(msg)
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > solana.MaxTransactionSize {
+		return nil, fmt.Errorf("transaction size %d exceeds %d bytes", len(b), solana.MaxTransactionSize)
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,197 @@
+package v6_test
+
+import (
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/utils"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTransaction(t *testing.T) {
+	c := cassetteClient(t, "swap_instructions")
+	quoteResponse, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+	require.NoError(t, err)
+	instructions, err := c.SwapInstructions(v6.SwapParams{
+		UserPublicKey:    userPublicKey,
+		QuoteResponse:    quoteResponse,
+		WrapAndUnwrapSol: utils.Pointer(true),
+	})
+	require.NoError(t, err)
+	require.Len(t, instructions.AddressLookupTableAddresses, 1)
+
+	var (
+		blockhash = solana.MustHashFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N")
+		wSolATA   = solana.MustPublicKeyFromBase58("7Jbm4XGnLPZ6qe4JRQEZ5fiEikdrPThXGJzXDb4oX6yn")
+		tables    = []solana.AddressLookupTable{{
+			Key:       instructions.AddressLookupTableAddresses[0],
+			Addresses: []solana.PublicKey{solana.TokenProgramID, wSolATA, wSolMint},
+		}}
+		memo   = solana.Instruction{ProgramID: solana.MustPublicKeyFromBase58("MemoSq4gqABAXKb96qnH8TuNmMtNiJ9mNt1EHwkWN4m"), Data: []byte("before")}
+		notify = solana.Instruction{
+			ProgramID: solana.SystemProgramID,
+			Accounts:  []solana.AccountMeta{solana.Meta(userPublicKey, true, true), solana.Meta(usdcMint, false, true)},
+			Data:      []byte{2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0},
+		}
+	)
+
+	t.Run("instructions are ordered", func(t *testing.T) {
+		ixs, err := instructions.Instructions([]solana.Instruction{memo}, []solana.Instruction{notify})
+		require.NoError(t, err)
+
+		programs := make([]solana.PublicKey, len(ixs))
+		for i, ix := range ixs {
+			programs[i] = ix.ProgramID
+		}
+		assert.Equal(t, []solana.PublicKey{
+			solana.ComputeBudgetProgramID,
+			solana.AssociatedTokenProgramID,
+			memo.ProgramID,
+			solana.JupiterAggregatorV6ProgramID,
+			solana.TokenProgramID,
+			solana.SystemProgramID,
+		}, programs)
+		assert.Equal(t, []byte{2, 0xc0, 0x5c, 0x15, 0}, ixs[0].Data)
+	})
+
+	t.Run("builds a compressed v0 transaction", func(t *testing.T) {
+		tx, err := instructions.BuildTransaction(v6.BuildTransactionOptions{
+			Payer:               userPublicKey,
+			RecentBlockhash:     blockhash,
+			AddressLookupTables: tables,
+			BeforeSwap:          []solana.Instruction{memo},
+			AfterSwap:           []solana.Instruction{notify},
+		})
+		require.NoError(t, err)
+
+		msg := tx.Message
+		assert.Equal(t, solana.MessageVersionV0, msg.Version)
+		assert.Equal(t, blockhash, msg.RecentBlockhash)
+		assert.Equal(t, []solana.PublicKey{userPublicKey}, msg.Signers())
+		assert.Len(t, tx.Signatures, 1)
+		require.Len(t, msg.Instructions, 6)
+
+		// The wSOL account and mint are loaded from the table, the token program is invoked so it stays static.
+		require.Len(t, msg.AddressTableLookups, 1)
+		assert.Equal(t, []uint8{1}, msg.AddressTableLookups[0].WritableIndexes)
+		assert.Equal(t, []uint8{2}, msg.AddressTableLookups[0].ReadonlyIndexes)
+		assert.NotContains(t, msg.AccountKeys, wSolATA)
+		assert.NotContains(t, msg.AccountKeys, wSolMint)
+		assert.Contains(t, msg.AccountKeys, solana.TokenProgramID)
+
+		b, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		decoded, err := solana.TransactionFromBytes(b)
+		require.NoError(t, err)
+		assert.Equal(t, tx, decoded)
+	})
+
+	t.Run("lookup tables must be resolved", func(t *testing.T) {
+		_, err := instructions.BuildTransaction(v6.BuildTransactionOptions{Payer: userPublicKey, RecentBlockhash: blockhash})
+		assert.Error(t, err)
+	})
+
+	t.Run("required options", func(t *testing.T) {
+		_, err := instructions.BuildTransaction(v6.BuildTransactionOptions{RecentBlockhash: blockhash, AddressLookupTables: tables})
+		assert.Error(t, err)
+		_, err = instructions.BuildTransaction(v6.BuildTransactionOptions{Payer: userPublicKey, AddressLookupTables: tables})
+		assert.Error(t, err)
+	})
+
+	t.Run("transaction too large", func(t *testing.T) {
+		var accounts []solana.AccountMeta
+		for i := 0; i < 40; i++ {
+			var k solana.PublicKey
+			k[0], k[1] = byte(i), 1
+			accounts = append(accounts, solana.Meta(k, false, false))
+		}
+		_, err := instructions.BuildTransaction(v6.BuildTransactionOptions{
+			Payer:               userPublicKey,
+			RecentBlockhash:     blockhash,
+			AddressLookupTables: tables,
+			AfterSwap:           []solana.Instruction{{ProgramID: solana.SystemProgramID, Accounts: accounts}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid instruction data", func(t *testing.T) {
+		broken := *instructions
+		broken.SwapInstruction.Data = "not base64!"
+		_, err := broken.Instructions(nil, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestTokenLedgerInstructions(t *testing.T) {
+	c := cassetteClient(t, "swap_instructions_token_ledger")
+	quoteResponse, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+	require.NoError(t, err)
+	instructions, err := c.SwapInstructions(v6.SwapParams{
+		UserPublicKey:    userPublicKey,
+		QuoteResponse:    quoteResponse,
+		WrapAndUnwrapSol: utils.Pointer(false),
+		UseTokenLedger:   utils.Pointer(true),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, instructions.TokenLedgerInstruction)
+
+	var (
+		wSolATA     = solana.MustPublicKeyFromBase58("7Jbm4XGnLPZ6qe4JRQEZ5fiEikdrPThXGJzXDb4oX6yn")
+		tokenLedger = solana.MustPublicKeyFromBase58("DAr8Qy1gj7YM5KMY5iDZtNVZ624mPg1hrCkAtm4VhcVv")
+		source      = solana.MustPublicKeyFromBase58("EpVTaMyUkynvs9FZq8t3dzeazUfmzE73fFrQCC5KfKEz")
+		transfer    = (&solana.TokenTransfer{Source: source, Destination: wSolATA, Owner: userPublicKey, Amount: 100000}).Instruction()
+	)
+
+	decoded, err := instructions.TokenLedgerInstruction.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, &solana.JupiterSetTokenLedger{TokenLedger: tokenLedger, TokenAccount: wSolATA}, decoded)
+
+	t.Run("transfer then swap", func(t *testing.T) {
+		ixs, err := instructions.TokenLedgerInstructions(transfer)
+		require.NoError(t, err)
+
+		names := make([]string, len(ixs))
+		for i, ix := range ixs {
+			decoded, err := solana.DecodeInstruction(ix)
+			require.NoError(t, err)
+			names[i] = decoded.Name()
+		}
+		assert.Equal(t, []string{"SetComputeUnitLimit", "SetTokenLedger", "Transfer", "RouteWithTokenLedger"}, names)
+		assert.Equal(t, transfer, ixs[2])
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		tx, err := instructions.BuildTransaction(v6.BuildTransactionOptions{
+			Payer:           userPublicKey,
+			RecentBlockhash: solana.MustHashFromBase58("EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"),
+			AddressLookupTables: []solana.AddressLookupTable{{
+				Key:       instructions.AddressLookupTableAddresses[0],
+				Addresses: []solana.PublicKey{solana.TokenProgramID, tokenLedger},
+			}},
+			BeforeSwap: []solana.Instruction{transfer},
+		})
+		require.NoError(t, err)
+		assert.Len(t, tx.Message.Instructions, 4)
+	})
+
+	t.Run("no transfer", func(t *testing.T) {
+		_, err := instructions.TokenLedgerInstructions()
+		assert.ErrorContains(t, err, "at least one transfer instruction is required")
+	})
+
+	t.Run("no token ledger", func(t *testing.T) {
+		withoutLedger := *instructions
+		withoutLedger.TokenLedgerInstruction = nil
+		_, err := withoutLedger.TokenLedgerInstructions(transfer)
+		assert.ErrorContains(t, err, "no token ledger instruction")
+	})
+
+	t.Run("swap without token ledger", func(t *testing.T) {
+		route := *instructions
+		route.SwapInstruction.Data = "5RfLl3rjrSoBAAAAJmQAAaCGAQAAAAAAXToAAAAAAAAyAAA="
+		_, err := route.TokenLedgerInstructions(transfer)
+		assert.ErrorContains(t, err, "swap instruction Route does not use the token ledger")
+	})
+}
@@ -0,0 +1,467 @@
+// Package cassette records HTTP interactions with the Jupiter API to a fixture file
+// and replays them deterministically.
+//
+// Both Recorder and Replayer are http.RoundTrippers, so they plug into v6.Client
+// through v6.WithHTTPClient:
+//
+//	rec := cassette.NewRecorder("testdata/quote.json")
+//	c := v6.NewClient(v6.WithHTTPClient(rec.Client()))
+//	// ... make calls against the real API ...
+//	err := rec.Save()
+//
+//	rep, err := cassette.NewReplayer("testdata/quote.json")
+//	c := v6.NewClient(v6.WithHTTPClient(rep.Client()))
+//	// ... the same calls are now served from the fixture ...
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces scrubbed secrets in recorded interactions.
+const Redacted = "[REDACTED]"
+
+// ErrUnmatchedRequest is returned by a Replayer for a request that matches no recorded interaction.
+var ErrUnmatchedRequest = errors.New("cassette: no recorded interaction matches request")
+
+// sensitiveHeaders are scrubbed from every recorded interaction.
+var sensitiveHeaders = []string{"X-Api-Key", "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+type (
+	// Cassette is a list of recorded HTTP interactions.
+	Cassette struct {
+		Interactions []Interaction `json:"interactions"`
+	}
+
+	// Interaction is a recorded request/response pair.
+	Interaction struct {
+		Request  RecordedRequest  `json:"request"`
+		Response RecordedResponse `json:"response"`
+	}
+
+	// RecordedRequest is the recorded part of an HTTP request.
+	RecordedRequest struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body,omitempty"`
+	}
+
+	// RecordedResponse is the recorded part of an HTTP response.
+	RecordedResponse struct {
+		StatusCode int         `json:"statusCode"`
+		Header     http.Header `json:"header,omitempty"`
+		Body       string      `json:"body"`
+	}
+
+	// Option configures a Recorder or a Replayer.
+	Option func(*config)
+
+	// Matcher reports whether a request matches a recorded one.
+	Matcher func(r *http.Request, body []byte, recorded RecordedRequest) bool
+
+	config struct {
+		transport http.RoundTripper
+		secrets   []string
+		scrubbers []func(*Interaction)
+		matcher   Matcher
+	}
+)
+
+// WithTransport returns an Option that sets the transport used by a Recorder to reach the real API.
+// Default is http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *config) {
+		c.transport = rt
+	}
+}
+
+// WithSecrets returns an Option that scrubs the given values from recorded URLs, headers and bodies.
+// A Replayer scrubs them from incoming requests before matching, so requests carrying
+// the real values match the scrubbed recordings.
+func WithSecrets(secrets ...string) Option {
+	return func(c *config) {
+		for _, s := range secrets {
+			if s != "" {
+				c.secrets = append(c.secrets, s)
+			}
+		}
+	}
+}
+
+// WithScrubber returns an Option that runs fn on every interaction before it is recorded.
+// A Replayer runs it on incoming requests before matching, with an empty response.
+func WithScrubber(fn func(*Interaction)) Option {
+	return func(c *config) {
+		c.scrubbers = append(c.scrubbers, fn)
+	}
+}
+
+// WithMatcher returns an Option that replaces the request matcher of a Replayer.
+// Default is DefaultMatcher.
+func WithMatcher(m Matcher) Option {
+	return func(c *config) {
+		c.matcher = m
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		transport: http.DefaultTransport,
+		matcher:   DefaultMatcher,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Load reads a cassette from the given file.
+func Load(path string) (*Cassette, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(buf, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette to the given file, creating parent directories as needed.
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// DefaultMatcher matches requests by method, path, query and body.
+// Scheme and host are ignored, so a cassette can be replayed against any API URL.
+// JSON bodies are compared semantically.
+func DefaultMatcher(r *http.Request, body []byte, recorded RecordedRequest) bool {
+	if r.Method != recorded.Method {
+		return false
+	}
+
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	if r.URL.Path != u.Path || !reflect.DeepEqual(r.URL.Query(), u.Query()) {
+		return false
+	}
+
+	return bodiesEqual(body, []byte(recorded.Body))
+}
+
+func bodiesEqual(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
+
+// Recorder is an http.RoundTripper that forwards requests to a real transport and records the interactions.
+// It is safe for concurrent use.
+type Recorder struct {
+	path string
+	cfg  *config
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder that writes interactions to the given file on Save.
+func NewRecorder(path string, opts ...Option) *Recorder {
+	return &Recorder{
+		path: path,
+		cfg:  newConfig(opts),
+	}
+}
+
+// Client returns an http.Client using the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, send, err := requestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	resp, err := r.cfg.transport.RoundTrip(send)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	i := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+	r.cfg.scrub(&i)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Interactions returns the interactions recorded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the recorder's file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+// scrub removes secrets from the interaction.
+func (c *config) scrub(i *Interaction) {
+	for _, h := range sensitiveHeaders {
+		if i.Request.Header.Get(h) != "" {
+			i.Request.Header.Set(h, Redacted)
+		}
+		if i.Response.Header.Get(h) != "" {
+			i.Response.Header.Set(h, Redacted)
+		}
+	}
+
+	for _, secret := range c.secrets {
+		i.Request.URL = strings.ReplaceAll(i.Request.URL, secret, Redacted)
+		i.Request.Body = strings.ReplaceAll(i.Request.Body, secret, Redacted)
+		i.Response.Body = strings.ReplaceAll(i.Response.Body, secret, Redacted)
+		scrubHeader(i.Request.Header, secret)
+		scrubHeader(i.Response.Header, secret)
+	}
+
+	for _, fn := range c.scrubbers {
+		fn(i)
+	}
+}
+
+// scrubRequest returns a copy of the request and its body scrubbed like a recorded interaction,
+// so a request carrying secrets matches its scrubbed recording.
+func (c *config) scrubRequest(req *http.Request, body []byte) (*http.Request, []byte) {
+	i := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+	}
+	c.scrub(&i)
+
+	scrubbed := req.Clone(req.Context())
+	scrubbed.Header = i.Request.Header
+	if u, err := url.Parse(i.Request.URL); err == nil {
+		scrubbed.URL = u
+	}
+
+	return scrubbed, []byte(i.Request.Body)
+}
+
+func scrubHeader(h http.Header, secret string) {
+	for k, vs := range h {
+		for j, v := range vs {
+			h[k][j] = strings.ReplaceAll(v, secret, Redacted)
+		}
+	}
+}
+
+// Replayer is an http.RoundTripper that serves responses from a cassette.
+// Each recorded interaction is served once, in order of recording among matching ones.
+// It is safe for concurrent use.
+type Replayer struct {
+	cfg *config
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer returns a Replayer serving the interactions recorded in the given file.
+func NewReplayer(path string, opts ...Option) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayerFromCassette(c, opts...), nil
+}
+
+// NewReplayerFromCassette returns a Replayer serving the interactions of the given cassette.
+func NewReplayerFromCassette(c *Cassette, opts ...Option) *Replayer {
+	return &Replayer{
+		cfg:      newConfig(opts),
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}
+}
+
+// Client returns an http.Client using the replayer as transport.
+func (r *Replayer) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper. It fails with ErrUnmatchedRequest
+// if no unused recorded interaction matches the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, send, err := requestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if send.Body != nil {
+		_ = send.Body.Close()
+	}
+
+	match, body := r.cfg.scrubRequest(req, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for idx, i := range r.cassette.Interactions {
+		if r.used[idx] || !r.cfg.matcher(match, body, i.Request) {
+			continue
+		}
+		r.used[idx] = true
+
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrUnmatchedRequest, req.Method, req.URL.RequestURI())
+}
+
+// Unused returns the recorded interactions that have not been replayed yet.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for idx, i := range r.cassette.Interactions {
+		if !r.used[idx] {
+			unused = append(unused, i)
+		}
+	}
+
+	return unused
+}
+
+// requestBody returns the body of the request along with the request to send in its place, without
+// modifying the request as http.RoundTripper requires. The body is read from GetBody if it is set,
+// in which case req is sent as is, or else from a clone of the request sending an in-memory copy.
+func requestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		buf, err := readBody(&body)
+		if err != nil {
+			return nil, nil, err
+		}
+		return buf, req, nil
+	}
+
+	send := req.Clone(req.Context())
+	buf, err := readBody(&send.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf, send, nil
+}
+
+// readBody reads the body and replaces it with an in-memory copy, so it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	buf, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(buf))
+
+	return buf, nil
+}
@@ -0,0 +1,183 @@
+package cassette_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/cassette"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	wSolMint      = solana.WrappedSOLMint
+	usdcMint      = solana.USDCMint
+	userPublicKey = solana.MustPublicKeyFromBase58("8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W")
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	const apiKey = "super-secret"
+
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	quoteParams := v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000}
+
+	// Record.
+	rec := cassette.NewRecorder(path, cassette.WithSecrets(userPublicKey.String()))
+	c := v6.NewClient(v6.WithHTTPClient(rec.Client()), v6.WithAPIURL(srv.URL), v6.WithAPIKey(apiKey))
+
+	recordedQuote, err := c.Quote(quoteParams)
+	require.NoError(t, err)
+	recordedTx, err := c.Swap(v6.SwapParams{QuoteResponse: recordedQuote, UserPublicKey: userPublicKey})
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+
+	// Secrets are scrubbed.
+	loaded, err := cassette.Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Interactions, 2)
+	for _, i := range loaded.Interactions {
+		assert.Equal(t, cassette.Redacted, i.Request.Header.Get(v6.HeaderAPIKey))
+		assert.NotContains(t, i.Request.Body, userPublicKey.String())
+	}
+
+	// Replay against a different API URL with the server gone.
+	srv.Close()
+	rep, err := cassette.NewReplayer(path, cassette.WithSecrets(userPublicKey.String()))
+	require.NoError(t, err)
+	c = v6.NewClient(v6.WithHTTPClient(rep.Client()), v6.WithAPIURL("http://replay.invalid"))
+
+	quote, err := c.Quote(quoteParams)
+	require.NoError(t, err)
+	assert.Equal(t, recordedQuote, quote)
+
+	tx, err := c.Swap(v6.SwapParams{QuoteResponse: quote, UserPublicKey: userPublicKey})
+	require.NoError(t, err)
+	assert.Equal(t, recordedTx, tx)
+	assert.Empty(t, rep.Unused())
+
+	// Interactions are served once.
+	_, err = c.Quote(quoteParams)
+	require.True(t, errors.Is(err, cassette.ErrUnmatchedRequest))
+}
+
+func TestRecorderLeavesRequestUnchanged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		body io.Reader
+	}{
+		{name: "with GetBody", body: strings.NewReader(`{"amount":1}`)},
+		{name: "without GetBody", body: struct{ io.Reader }{strings.NewReader(`{"amount":1}`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, srv.URL, tt.body)
+			require.NoError(t, err)
+			body := req.Body
+
+			rec := cassette.NewRecorder(filepath.Join(t.TempDir(), "cassette.json"))
+			resp, err := rec.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.True(t, req.Body == body, "request body replaced")
+			echoed, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"amount":1}`, string(echoed))
+			require.Len(t, rec.Interactions(), 1)
+			assert.Equal(t, `{"amount":1}`, rec.Interactions()[0].Request.Body)
+		})
+	}
+}
+
+func TestReplayerUnmatched(t *testing.T) {
+	rep := cassette.NewReplayerFromCassette(&cassette.Cassette{
+		Interactions: []cassette.Interaction{
+			{
+				Request:  cassette.RecordedRequest{Method: http.MethodGet, URL: "https://quote-api.jup.ag/v6/quote?amount=1&inputMint=" + wSolMint.String() + "&outputMint=" + usdcMint.String()},
+				Response: cassette.RecordedResponse{StatusCode: http.StatusOK, Body: `{"inAmount":"1"}`},
+			},
+		},
+	})
+	c := v6.NewClient(v6.WithHTTPClient(rep.Client()))
+
+	_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 2})
+	require.True(t, errors.Is(err, cassette.ErrUnmatchedRequest))
+	assert.Len(t, rep.Unused(), 1)
+
+	quote, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 1})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, quote.InAmount)
+	assert.Empty(t, rep.Unused())
+}
+
+func TestReplayerScrubsSecrets(t *testing.T) {
+	const token = "secret-token"
+	recorded := &cassette.Cassette{
+		Interactions: []cassette.Interaction{
+			{
+				Request: cassette.RecordedRequest{
+					Method: http.MethodGet,
+					URL:    "https://quote-api.jup.ag/v6/quote?amount=1&taker=" + url.QueryEscape(cassette.Redacted),
+				},
+				Response: cassette.RecordedResponse{StatusCode: http.StatusOK, Body: `{"inAmount":"1"}`},
+			},
+			{
+				Request: cassette.RecordedRequest{
+					Method: http.MethodPost,
+					URL:    "https://quote-api.jup.ag/v6/swap",
+					Header: http.Header{"Authorization": {cassette.Redacted}},
+					Body:   `{"userPublicKey":"` + cassette.Redacted + `"}`,
+				},
+				Response: cassette.RecordedResponse{StatusCode: http.StatusOK, Body: `{"swapTransaction":"tx"}`},
+			},
+		},
+	}
+
+	// The matcher also requires the sensitive headers to be scrubbed.
+	matcher := func(r *http.Request, body []byte, i cassette.RecordedRequest) bool {
+		return r.Header.Get("Authorization") == i.Header.Get("Authorization") && cassette.DefaultMatcher(r, body, i)
+	}
+	send := func(rep *cassette.Replayer) error {
+		resp, err := rep.Client().Get("http://replay.invalid/v6/quote?amount=1&taker=" + userPublicKey.String())
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		req, err := http.NewRequest(http.MethodPost, "http://replay.invalid/v6/swap", strings.NewReader(`{"userPublicKey":"`+userPublicKey.String()+`"}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err = rep.Client().Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		return nil
+	}
+
+	rep := cassette.NewReplayerFromCassette(recorded, cassette.WithSecrets(userPublicKey.String()), cassette.WithMatcher(matcher))
+	require.NoError(t, send(rep))
+	assert.Empty(t, rep.Unused())
+
+	// Without the secrets, requests carrying the real values do not match the scrubbed recordings.
+	rep = cassette.NewReplayerFromCassette(recorded, cassette.WithMatcher(matcher))
+	assert.ErrorIs(t, send(rep), cassette.ErrUnmatchedRequest)
+}
@@ -2,9 +2,9 @@ package v6
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -26,11 +26,28 @@ type (
 		endpointQuote            string
 		endpointSwap             string
 		endpointSwapInstructions string
+
+		retryPolicy       *RetryPolicy
+		rateLimiters      map[Operation]*RateLimiter
+		rateLimitFailFast bool
+
+		apiKey     string
+		headers    http.Header
+		headerFunc HeaderFunc
+		secrets    []string
+
+		middlewares []Middleware
+
+		maxResponseBodySize int64
+		skipValidation      bool
 	}
 
 	// ClientOption is a function that can be used to configure a Jupiter client.
 	ClientOption func(*Client)
 
+	// Operation identifies a Jupiter API call made by the client.
+	Operation string
+
 	// Response is a generic response structure.
 	Response struct {
 		Data        json.RawMessage `json:"data"`
@@ -39,6 +56,13 @@ type (
 	}
 )
 
+// Predefined operations.
+const (
+	OperationQuote            Operation = "Quote"
+	OperationSwap             Operation = "Swap"
+	OperationSwapInstructions Operation = "SwapInstructions"
+)
+
 // NewClient returns a new Jupiter client.
 func NewClient(opts ...ClientOption) *Client {
 	c := &Client{
@@ -50,6 +74,8 @@ func NewClient(opts ...ClientOption) *Client {
 		endpointQuote:            "/quote",
 		endpointSwap:             "/swap",
 		endpointSwapInstructions: "/swap-instructions",
+
+		maxResponseBodySize: defaultMaxResponseBodySize,
 	}
 
 	for _, opt := range opts {
@@ -59,11 +85,11 @@ func NewClient(opts ...ClientOption) *Client {
 	return c
 }
 
-// get makes a GET request to the specified endpoint with the given parameters.
+// get makes a GET request to the specified endpoint with the parameters of the call.
 // It returns the response as is without parsing or any error encountered.
-// The caller is responsible for closing the response body.
-func (c *Client) get(endpoint string, params interface{}) (*http.Response, error) {
-	uv, err := utils.StructToUrlValues(params)
+// The caller is responsible for closing the response body, see decodeResponse.
+func (c *Client) get(ctx context.Context, call *Call, endpoint string) (*http.Response, error) {
+	uv, err := utils.StructToUrlValues(call.Params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert params to url values: %w", err)
 	}
@@ -77,102 +103,197 @@ func (c *Client) get(endpoint string, params interface{}) (*http.Response, error
 		parsedURL.RawQuery = uv.Encode()
 	}
 
-	req, err := http.NewRequest(http.MethodGet, parsedURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GET request: %w", err)
 	}
 	req.Header.Set("Accept", ContentTypeJSON)
+	if err := c.setHeaders(ctx, call, req.Header); err != nil {
+		return nil, fmt.Errorf("failed to set GET request headers: %w", c.redactError(err, req.Header))
+	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(call.Operation, endpoint, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make GET request: %w", err)
+		return nil, fmt.Errorf("failed to make GET request: %w", c.redactError(err, req.Header))
 	}
 
 	return resp, nil
 }
 
-// postRaw makes a POST request to the specified URL with the given parameters.
+// post makes a POST request to the specified endpoint with the parameters of the call.
 // It returns the response as is without parsing or any error encountered.
-// The caller is responsible for closing the response body.
-func (c *Client) post(endpoint string, params interface{}) (*http.Response, error) {
-	body, err := json.Marshal(params)
+// The caller is responsible for closing the response body, see decodeResponse.
+func (c *Client) post(ctx context.Context, call *Call, endpoint string) (*http.Response, error) {
+	body, err := json.Marshal(call.Params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal POST params: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.apiURL+endpoint, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+endpoint, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create POST request: %w", err)
 	}
 	req.Header.Set("Content-Type", ContentTypeJSON)
 	req.Header.Set("Accept", ContentTypeJSON)
+	if err := c.setHeaders(ctx, call, req.Header); err != nil {
+		return nil, fmt.Errorf("failed to set POST request headers: %w", c.redactError(err, req.Header))
+	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(call.Operation, endpoint, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make POST request: %w", err)
+		return nil, fmt.Errorf("failed to make POST request: %w", c.redactError(err, req.Header))
 	}
 
 	return resp, nil
 }
 
+// do sends the request, retrying it according to the client's retry policy if one is set.
+func (c *Client) do(op Operation, endpoint string, req *http.Request) (*http.Response, error) {
+	if c.retryPolicy == nil {
+		if err := c.waitRateLimit(req.Context(), op); err != nil {
+			return nil, err
+		}
+		return c.client.Do(req)
+	}
+
+	return c.doWithRetry(op, endpoint, req)
+}
+
 // Quote returns a quote for a given input mint, output mint and amount
 func (c *Client) Quote(params QuoteParams) (*QuoteResponse, error) {
-	resp, err := c.get(c.endpointQuote, params)
+	return c.QuoteContext(context.Background(), params)
+}
+
+// QuoteContext is like Quote but uses the given context for the request.
+// Cancelling the context aborts the in-flight request.
+func (c *Client) QuoteContext(ctx context.Context, params QuoteParams) (*QuoteResponse, error) {
+	result, err := c.invoke(ctx, OperationQuote, params, c.quote)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make quote request: %w", err)
+		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+
+	quotes, ok := result.(*QuoteResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected quote result type: %T", result)
+	}
+
+	return quotes, nil
+}
+
+// quote is the final handler of the quote middleware chain.
+func (c *Client) quote(ctx context.Context, call *Call) (interface{}, error) {
+	if err := c.validateParams(call); err != nil {
+		return nil, err
 	}
 
-	buf, err := io.ReadAll(resp.Body)
+	resp, err := c.get(ctx, call, c.endpointQuote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make quote request: %w", err)
+	}
 
 	var quotes QuoteResponse
-	if err := json.Unmarshal(buf, &quotes); err != nil {
-		return nil, fmt.Errorf("failed to parse quote response: %w", err)
+	if err := c.decodeResponse(http.MethodGet, c.endpointQuote, resp, &quotes); err != nil {
+		return nil, err
 	}
 
 	return &quotes, nil
 }
 
 // Swap returns swap base64 serialized transaction for a route.
-// The caller is responsible for signing the transactions.
+// The caller is responsible for signing the transaction, see SignSwapTransaction.
+// Use SwapWithDetails to get the whole response, including the transaction expiry.
 func (c *Client) Swap(params SwapParams) (string, error) {
-	resp, err := c.post(c.endpointSwap, params)
+	return c.SwapContext(context.Background(), params)
+}
+
+// SwapContext is like Swap but uses the given context for the request.
+// Cancelling the context aborts the in-flight request.
+func (c *Client) SwapContext(ctx context.Context, params SwapParams) (string, error) {
+	response, err := c.SwapWithDetailsContext(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	return response.SwapTransaction, nil
+}
+
+// SwapWithDetails is like Swap but returns the whole swap response: the transaction along with
+// its last valid block height, prioritization fee, compute unit limit and dynamic slippage report.
+func (c *Client) SwapWithDetails(params SwapParams) (*SwapResponse, error) {
+	return c.SwapWithDetailsContext(context.Background(), params)
+}
+
+// SwapWithDetailsContext is like SwapWithDetails but uses the given context for the request.
+// Cancelling the context aborts the in-flight request.
+func (c *Client) SwapWithDetailsContext(ctx context.Context, params SwapParams) (*SwapResponse, error) {
+	result, err := c.invoke(ctx, OperationSwap, params, c.swap)
 	if err != nil {
-		return "", fmt.Errorf("failed to make swap request: %w", err)
+		return nil, err
+	}
+
+	response, ok := result.(*SwapResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected swap result type: %T", result)
+	}
+
+	return response, nil
+}
+
+// swap is the final handler of the swap middleware chain.
+func (c *Client) swap(ctx context.Context, call *Call) (interface{}, error) {
+	if err := c.validateParams(call); err != nil {
+		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+
+	resp, err := c.post(ctx, call, c.endpointSwap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make swap request: %w", err)
 	}
 
 	var response SwapResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	if err := c.decodeResponse(http.MethodPost, c.endpointSwap, resp, &response); err != nil {
+		return nil, err
 	}
 
-	return response.SwapTransaction, nil
+	return &response, nil
 }
 
 // SwapInstructions Returns instructions that you can use from the quote you get from /quote.
 // The caller is responsible for signing the transactions.
 func (c *Client) SwapInstructions(params SwapParams) (*SwapInstructionsResp, error) {
-	resp, err := c.post(c.endpointSwapInstructions, params)
+	return c.SwapInstructionsContext(context.Background(), params)
+}
+
+// SwapInstructionsContext is like SwapInstructions but uses the given context for the request.
+// Cancelling the context aborts the in-flight request.
+func (c *Client) SwapInstructionsContext(ctx context.Context, params SwapParams) (*SwapInstructionsResp, error) {
+	result, err := c.invoke(ctx, OperationSwapInstructions, params, c.swapInstructions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make swap request: %w", err)
+		return nil, err
+	}
+
+	response, ok := result.(*SwapInstructionsResp)
+	if !ok {
+		return nil, fmt.Errorf("unexpected swap instructions result type: %T", result)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+
+	return response, nil
+}
+
+// swapInstructions is the final handler of the swap instructions middleware chain.
+func (c *Client) swapInstructions(ctx context.Context, call *Call) (interface{}, error) {
+	if err := c.validateParams(call); err != nil {
+		return nil, err
 	}
 
-	buf, err := io.ReadAll(resp.Body)
+	resp, err := c.post(ctx, call, c.endpointSwapInstructions)
 	if err != nil {
-		return nil, fmt.Errorf("io.ReadAll: %w", err)
+		return nil, fmt.Errorf("failed to make swap instructions request: %w", err)
 	}
 
 	var response SwapInstructionsResp
-	if err := json.Unmarshal(buf, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse quote response: %w", err)
+	if err := c.decodeResponse(http.MethodPost, c.endpointSwapInstructions, resp, &response); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
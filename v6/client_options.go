@@ -32,3 +32,91 @@ func WithEndpointSwap(endpointSwap string) ClientOption {
 		c.endpointSwap = endpointSwap
 	}
 }
+
+// WithRetryPolicy returns a ClientOption that makes the Jupiter client retry failed requests according to the given policy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithRateLimiter returns a ClientOption that throttles the given operations with the given rate limiter.
+// If no operations are given, the limiter is shared by all operations.
+// Passing the same limiter to several clients makes them share the budget.
+func WithRateLimiter(limiter *RateLimiter, ops ...Operation) ClientOption {
+	return func(c *Client) {
+		if len(ops) == 0 {
+			ops = []Operation{OperationQuote, OperationSwap, OperationSwapInstructions}
+		}
+		if c.rateLimiters == nil {
+			c.rateLimiters = make(map[Operation]*RateLimiter, len(ops))
+		}
+		for _, op := range ops {
+			c.rateLimiters[op] = limiter
+		}
+	}
+}
+
+// WithRateLimitFailFast returns a ClientOption that makes rate limited requests fail with ErrRateLimitExceeded
+// instead of waiting for the rate limiter budget to be replenished.
+func WithRateLimitFailFast(failFast bool) ClientOption {
+	return func(c *Client) {
+		c.rateLimitFailFast = failFast
+	}
+}
+
+// WithAPIKey returns a ClientOption that sends the given API key in the x-api-key header of every request.
+// The key is redacted from errors returned by the client.
+func WithAPIKey(apiKey string) ClientOption {
+	return func(c *Client) {
+		c.apiKey = apiKey
+		c.addSecret(apiKey)
+	}
+}
+
+// WithHeader returns a ClientOption that sets the given header on every request.
+// Values of sensitive headers, such as Authorization, are redacted from errors returned by the client.
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		c.headers.Set(key, value)
+		if isSensitiveHeader(key) {
+			c.addSecret(value)
+		}
+	}
+}
+
+// WithHeaderFunc returns a ClientOption that calls fn to mutate the headers of every request before it is sent.
+// It runs after the static headers and the API key are set, so it can override them.
+// Values of the sensitive headers it sets are redacted from errors returned by the client.
+func WithHeaderFunc(fn HeaderFunc) ClientOption {
+	return func(c *Client) {
+		c.headerFunc = fn
+	}
+}
+
+// WithMiddleware returns a ClientOption that appends the given middlewares to the client's middleware chain.
+// Middlewares run in the order they are added, the first one being the outermost.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, mw...)
+	}
+}
+
+// WithMaxResponseBodySize returns a ClientOption that limits the size of response bodies read by the Jupiter client.
+// Larger responses fail with ErrResponseTooLarge. Default is 10MiB.
+func WithMaxResponseBodySize(size int64) ClientOption {
+	return func(c *Client) {
+		c.maxResponseBodySize = size
+	}
+}
+
+// WithValidation returns a ClientOption that enables or disables client-side validation of request parameters,
+// see QuoteParams.Validate and SwapParams.Validate. Validation is enabled by default.
+func WithValidation(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.skipValidation = !enabled
+	}
+}
@@ -1,21 +1,61 @@
 package v6_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qiruos/jupiter/solana"
 	"github.com/qiruos/jupiter/utils"
 	"github.com/qiruos/jupiter/v6"
-	"testing"
+	"github.com/qiruos/jupiter/v6/cassette"
+	"github.com/qiruos/jupiter/v6/jupitertest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-const (
-	wSolMint = "So11111111111111111111111111111111111111112"
-	usdcMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+var (
+	wSolMint      = solana.WrappedSOLMint
+	usdcMint      = solana.USDCMint
+	userPublicKey = solana.MustPublicKeyFromBase58("8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W")
 )
 
+// cassetteClient returns a client replaying the named cassette from testdata/cassettes.
+// The committed cassettes are synthetic, see testdata/cassettes/README.md.
+// Set JUPITER_RECORD=1 to record the cassette against the live API.
+func cassetteClient(t *testing.T, name string) *v6.Client {
+	t.Helper()
+
+	path := filepath.Join("testdata", "cassettes", name+".json")
+	if os.Getenv("JUPITER_RECORD") != "" {
+		rec := cassette.NewRecorder(path, cassette.WithScrubber(func(i *cassette.Interaction) {
+			i.Response.Header.Del("Date")
+		}))
+		t.Cleanup(func() {
+			require.NoError(t, rec.Save())
+		})
+		return v6.NewClient(v6.WithHTTPClient(rec.Client()))
+	}
+
+	rep, err := cassette.NewReplayer(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.Empty(t, rep.Unused(), "not all recorded interactions were replayed")
+	})
+	return v6.NewClient(v6.WithHTTPClient(rep.Client()))
+}
+
 func TestQuote(t *testing.T) {
-	c := v6.NewClient()
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
 	quotes, err := c.Quote(v6.QuoteParams{
 		InputMint:        wSolMint,
 		OutputMint:       usdcMint,
@@ -31,11 +71,19 @@ func TestQuote(t *testing.T) {
 
 	assert.Equal(t, wSolMint, quotes.InputMint)
 	assert.Equal(t, usdcMint, quotes.OutputMint)
-	assert.Equal(t, "100000", quotes.InAmount)
+	assert.EqualValues(t, 100000, quotes.InAmount)
+
+	req, ok := srv.LastRequest(jupitertest.EndpointQuote)
+	require.True(t, ok)
+	assert.Equal(t, "true", req.Query.Get("onlyDirectRoutes"))
+	assert.Equal(t, []string{v6.DexRaydium, v6.DexOrcaV1}, req.Query["dexes"])
 }
 
 func TestSwap(t *testing.T) {
-	c := v6.NewClient()
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
 	var quoteResponse *v6.QuoteResponse
 	var err error
 
@@ -52,7 +100,7 @@ func TestSwap(t *testing.T) {
 
 	t.Run("create swap tx", func(t *testing.T) {
 		swapTx, err := c.Swap(v6.SwapParams{
-			UserPublicKey:    "8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W",
+			UserPublicKey:    userPublicKey,
 			QuoteResponse:    quoteResponse,
 			WrapAndUnwrapSol: utils.Pointer(true),
 		})
@@ -60,11 +108,59 @@ func TestSwap(t *testing.T) {
 		require.NotEmpty(t, swapTx)
 
 		//t.Log(swapTx)
+
+		req, ok := srv.LastRequest(jupitertest.EndpointSwap)
+		require.True(t, ok)
+		params, err := req.SwapParams()
+		require.NoError(t, err)
+		assert.Equal(t, userPublicKey, params.UserPublicKey)
+		assert.Equal(t, quoteResponse, params.QuoteResponse)
+	})
+}
+
+func TestSwapWithDetails(t *testing.T) {
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	quoteResponse, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+	require.NoError(t, err)
+
+	t.Run("whole response", func(t *testing.T) {
+		swap, err := c.SwapWithDetails(v6.SwapParams{UserPublicKey: userPublicKey, QuoteResponse: quoteResponse})
+		require.NoError(t, err)
+		assert.Equal(t, jupitertest.DefaultSwapTransaction, swap.SwapTransaction)
+		assert.EqualValues(t, jupitertest.DefaultLastValidBlockHeight, swap.LastValidBlockHeight)
+		assert.EqualValues(t, jupitertest.DefaultComputeUnitLimit, swap.ComputeUnitLimit)
+		require.NotNil(t, swap.PrioritizationType)
+		assert.NotNil(t, swap.PrioritizationType.ComputeBudget)
+		assert.Nil(t, swap.DynamicSlippageReport)
+		assert.Nil(t, swap.SimulationError)
+	})
+
+	t.Run("dynamic slippage", func(t *testing.T) {
+		swap, err := c.SwapWithDetails(v6.SwapParams{
+			UserPublicKey:   userPublicKey,
+			QuoteResponse:   quoteResponse,
+			DynamicSlippage: &v6.DynamicSlippage{MinBps: 50, MaxBps: 300},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, swap.DynamicSlippageReport)
+		assert.Equal(t, 300, swap.DynamicSlippageReport.SlippageBps)
+
+		req, ok := srv.LastRequest(jupitertest.EndpointSwap)
+		require.True(t, ok)
+		var body map[string]json.RawMessage
+		require.NoError(t, req.DecodeJSON(&body))
+		assert.JSONEq(t, `{"minBps":50,"maxBps":300}`, string(body["dynamicSlippage"]))
 	})
 }
 
 func TestSwapInstructions(t *testing.T) {
-	c := v6.NewClient()
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
 	var quoteResponse *v6.QuoteResponse
 	var err error
 
@@ -81,7 +177,7 @@ func TestSwapInstructions(t *testing.T) {
 
 	t.Run("create swap tx", func(t *testing.T) {
 		swapInstructions, err := c.SwapInstructions(v6.SwapParams{
-			UserPublicKey:    "8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W",
+			UserPublicKey:    userPublicKey,
 			QuoteResponse:    quoteResponse,
 			WrapAndUnwrapSol: utils.Pointer(true),
 		})
@@ -91,3 +187,108 @@ func TestSwapInstructions(t *testing.T) {
 		//t.Log(swapInstructions)
 	})
 }
+
+func TestQuoteReplay(t *testing.T) {
+	c := cassetteClient(t, "quote")
+	quotes, err := c.Quote(v6.QuoteParams{
+		InputMint:        wSolMint,
+		OutputMint:       usdcMint,
+		Amount:           100000,
+		OnlyDirectRoutes: true,
+		SwapMode:         v6.SwapModeExactIn,
+		Dexes:            []string{v6.DexRaydium, v6.DexOrcaV1},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, quotes)
+
+	assert.Equal(t, wSolMint, quotes.InputMint)
+	assert.Equal(t, usdcMint, quotes.OutputMint)
+	assert.EqualValues(t, 100000, quotes.InAmount)
+	require.Len(t, quotes.RoutePlan, 1)
+	assert.Equal(t, v6.DexRaydium, quotes.RoutePlan[0].SwapInfo.Label)
+}
+
+func TestSwapReplay(t *testing.T) {
+	c := cassetteClient(t, "swap")
+	quoteResponse, err := c.Quote(v6.QuoteParams{
+		InputMint:  wSolMint,
+		OutputMint: usdcMint,
+		Amount:     100000,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, quoteResponse)
+
+	swap, err := c.SwapWithDetails(v6.SwapParams{
+		UserPublicKey:    userPublicKey,
+		QuoteResponse:    quoteResponse,
+		WrapAndUnwrapSol: utils.Pointer(true),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, swap.SwapTransaction)
+	assert.EqualValues(t, 254838219, swap.LastValidBlockHeight)
+
+	tx, err := swap.Transaction()
+	require.NoError(t, err)
+	assert.Equal(t, solana.MessageVersionV0, tx.Message.Version)
+	assert.Equal(t, []solana.PublicKey{userPublicKey}, tx.Message.Signers())
+}
+
+func TestSwapInstructionsReplay(t *testing.T) {
+	c := cassetteClient(t, "swap_instructions")
+	quoteResponse, err := c.Quote(v6.QuoteParams{
+		InputMint:  wSolMint,
+		OutputMint: usdcMint,
+		Amount:     100000,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, quoteResponse)
+
+	swapInstructions, err := c.SwapInstructions(v6.SwapParams{
+		UserPublicKey:    userPublicKey,
+		QuoteResponse:    quoteResponse,
+		WrapAndUnwrapSol: utils.Pointer(true),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, swapInstructions)
+
+	assert.Equal(t, solana.JupiterAggregatorV6ProgramID, swapInstructions.SwapInstruction.ProgramId)
+	assert.Len(t, swapInstructions.AddressLookupTableAddresses, 1)
+}
+
+func TestContextCancellation(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c := v6.NewClient(v6.WithAPIURL(srv.URL))
+
+	t.Run("quote", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.QuoteContext(ctx, v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := c.SwapContext(ctx, v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("swap instructions", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.SwapInstructionsContext(ctx, v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
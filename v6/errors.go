@@ -0,0 +1,117 @@
+package v6
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Known Jupiter API error codes.
+const (
+	ErrorCodeCouldNotFindAnyRoute = "COULD_NOT_FIND_ANY_ROUTE"
+	ErrorCodeNoRoutesFound        = "NO_ROUTES_FOUND"
+	ErrorCodeTokenNotTradable     = "TOKEN_NOT_TRADABLE"
+	ErrorCodeCircularArbitrage    = "CIRCULAR_ARBITRAGE_IS_DISABLED"
+)
+
+// Sentinel errors that can be matched against an *APIError with errors.Is.
+var (
+	ErrNoRoute          = errors.New("no route found")
+	ErrTokenNotTradable = errors.New("token not tradable")
+	ErrBadRequest       = errors.New("bad request")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrServerError      = errors.New("server error")
+)
+
+// maxErrorBodySize limits how much of an error response body is kept.
+const maxErrorBodySize = 64 << 10
+
+// APIError is returned when the Jupiter API responds with a non-200 status code.
+type APIError struct {
+	Method     string // HTTP method of the failed request
+	Endpoint   string // endpoint of the failed request, e.g. "/quote"
+	StatusCode int    // HTTP status code
+	Body       []byte // raw response body, truncated to 64KiB
+	Code       string // Jupiter error code, e.g. "COULD_NOT_FIND_ANY_ROUTE", if present
+	Message    string // Jupiter error message, if present
+}
+
+// newAPIError builds an APIError from a non-200 response.
+// The response body is read but not closed. Secrets and sensitive headers echoed back by the server are redacted.
+func (c *Client) newAPIError(method, endpoint string, resp *http.Response) *APIError {
+	var header http.Header
+	if resp.Request != nil {
+		header = resp.Request.Header
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	body = []byte(c.redact(string(body), header))
+
+	apiErr := &APIError{
+		Method:     method,
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+
+	var payload struct {
+		Error     string `json:"error"`
+		Message   string `json:"message"`
+		ErrorCode string `json:"errorCode"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		apiErr.Code = payload.ErrorCode
+		apiErr.Message = payload.Error
+		if apiErr.Message == "" {
+			apiErr.Message = payload.Message
+		}
+	}
+
+	return apiErr
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "unexpected status code: %d", e.StatusCode)
+	if e.Endpoint != "" {
+		fmt.Fprintf(&sb, " from %s %s", e.Method, e.Endpoint)
+	}
+	if e.Code != "" {
+		fmt.Fprintf(&sb, ": %s", e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ": %s", e.Message)
+	} else if e.Code == "" && len(e.Body) > 0 {
+		fmt.Fprintf(&sb, ": %s", strings.TrimSpace(string(e.Body)))
+	}
+	return sb.String()
+}
+
+// Retryable reports whether the request may succeed if retried as is.
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return e.StatusCode >= http.StatusInternalServerError && e.StatusCode != http.StatusNotImplemented
+}
+
+// Is reports whether the error matches one of the sentinel errors of this package.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNoRoute:
+		return e.Code == ErrorCodeCouldNotFindAnyRoute || e.Code == ErrorCodeNoRoutesFound
+	case ErrTokenNotTradable:
+		return e.Code == ErrorCodeTokenNotTradable
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package v6_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		sentinel  error
+		code      string
+		message   string
+		retryable bool
+	}{
+		{
+			name:     "no route",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"Could not find any route","errorCode":"COULD_NOT_FIND_ANY_ROUTE"}`,
+			sentinel: v6.ErrNoRoute,
+			code:     v6.ErrorCodeCouldNotFindAnyRoute,
+			message:  "Could not find any route",
+		},
+		{
+			name:     "token not tradable",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"The token is not tradable","errorCode":"TOKEN_NOT_TRADABLE"}`,
+			sentinel: v6.ErrTokenNotTradable,
+			code:     v6.ErrorCodeTokenNotTradable,
+			message:  "The token is not tradable",
+		},
+		{
+			name:      "rate limited",
+			status:    http.StatusTooManyRequests,
+			body:      `{"message":"Too many requests"}`,
+			sentinel:  v6.ErrRateLimited,
+			message:   "Too many requests",
+			retryable: true,
+		},
+		{
+			name:      "server error with plain body",
+			status:    http.StatusBadGateway,
+			body:      "bad gateway",
+			sentinel:  v6.ErrServerError,
+			retryable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := v6.NewClient(v6.WithAPIURL(srv.URL))
+			_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+			require.Error(t, err)
+			require.ErrorIs(t, err, tt.sentinel)
+
+			var apiErr *v6.APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.body, string(apiErr.Body))
+			assert.Equal(t, tt.code, apiErr.Code)
+			assert.Equal(t, tt.message, apiErr.Message)
+			assert.Equal(t, "/quote", apiErr.Endpoint)
+			assert.Equal(t, tt.retryable, apiErr.Retryable())
+		})
+	}
+}
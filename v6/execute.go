@@ -0,0 +1,244 @@
+package v6
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+)
+
+const (
+	defaultExecutorResendInterval = 2 * time.Second
+	defaultExecutorPollInterval   = 500 * time.Millisecond
+)
+
+type (
+	// Executor sends signed swap transactions and waits for their outcome.
+	//
+	// The transaction is rebroadcast periodically until it reaches the configured commitment,
+	// fails on chain, or expires: once the block height passes the last valid block height of
+	// its blockhash, the transaction can no longer be processed and it is safe to build a new one.
+	Executor struct {
+		rpc *rpc.Client
+
+		commitment     rpc.Commitment
+		resendInterval time.Duration
+		pollInterval   time.Duration
+		preflight      bool
+	}
+
+	// ExecutorOption is a function that can be used to configure an Executor.
+	ExecutorOption func(*Executor)
+
+	// ExecutionStatus is the final status of a transaction sent by an Executor.
+	ExecutionStatus string
+
+	// ExecutionResult is the outcome of a transaction sent by an Executor.
+	ExecutionResult struct {
+		Status      ExecutionStatus
+		Signature   solana.Signature
+		Slot        uint64                // slot the transaction was processed in, 0 if it expired
+		Err         *rpc.TransactionError // on-chain error, set if Status is ExecutionFailed
+		Logs        []string              // program logs of a failed preflight simulation
+		Sends       int                   // number of times the transaction was sent
+		BlockHeight uint64                // last block height observed
+	}
+)
+
+// Execution statuses.
+const (
+	ExecutionConfirmed ExecutionStatus = "confirmed" // the transaction succeeded and reached the executor commitment
+	ExecutionFailed    ExecutionStatus = "failed"    // the transaction failed on chain or in the preflight simulation
+	ExecutionExpired   ExecutionStatus = "expired"   // the blockhash expired before the transaction was processed
+)
+
+// NewExecutor returns an Executor sending transactions through the given RPC client.
+func NewExecutor(client *rpc.Client, opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		rpc:            client,
+		commitment:     rpc.CommitmentConfirmed,
+		resendInterval: defaultExecutorResendInterval,
+		pollInterval:   defaultExecutorPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	if _, ok := commitmentLevels[e.commitment]; !ok {
+		e.commitment = rpc.CommitmentConfirmed
+	}
+	if e.resendInterval <= 0 {
+		e.resendInterval = defaultExecutorResendInterval
+	}
+	if e.pollInterval <= 0 {
+		e.pollInterval = defaultExecutorPollInterval
+	}
+
+	return e
+}
+
+// WithExecutorCommitment returns an ExecutorOption that sets the commitment a transaction must reach to be confirmed.
+// Commitments other than processed, confirmed and finalized keep the default, confirmed.
+func WithExecutorCommitment(commitment rpc.Commitment) ExecutorOption {
+	return func(e *Executor) {
+		e.commitment = commitment
+	}
+}
+
+// WithExecutorResendInterval returns an ExecutorOption that sets how often an unprocessed transaction is rebroadcast.
+// Default is 2s, which a non-positive interval keeps.
+func WithExecutorResendInterval(d time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.resendInterval = d
+	}
+}
+
+// WithExecutorPollInterval returns an ExecutorOption that sets how often the signature status and block height are polled.
+// Default is 500ms, which a non-positive interval keeps.
+func WithExecutorPollInterval(d time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.pollInterval = d
+	}
+}
+
+// WithExecutorPreflight returns an ExecutorOption that enables the preflight simulation of the first send,
+// so a transaction bound to fail is reported as failed without being broadcast. Default is disabled.
+func WithExecutorPreflight(enabled bool) ExecutorOption {
+	return func(e *Executor) {
+		e.preflight = enabled
+	}
+}
+
+// ExecuteSwap signs the transaction of the swap response and executes it until its LastValidBlockHeight.
+func (e *Executor) ExecuteSwap(ctx context.Context, swap *SwapResponse, signer solana.Signer) (*ExecutionResult, error) {
+	tx, err := swap.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Sign(ctx, signer); err != nil {
+		return nil, fmt.Errorf("failed to sign swap transaction: %w", err)
+	}
+
+	return e.Execute(ctx, tx, uint64(swap.LastValidBlockHeight))
+}
+
+// Execute sends the signed transaction and waits until it is confirmed, fails, or expires once the block
+// height passes lastValidBlockHeight, which is required. Only a cancelled context or an RPC failure of the first send is
+// returned as an error: the outcome of the transaction is reported in ExecutionResult.
+func (e *Executor) Execute(ctx context.Context, tx *solana.Transaction, lastValidBlockHeight uint64) (*ExecutionResult, error) {
+	if lastValidBlockHeight == 0 {
+		return nil, fmt.Errorf("lastValidBlockHeight is required")
+	}
+	if err := tx.Verify(); err != nil {
+		return nil, fmt.Errorf("transaction is not signed: %w", err)
+	}
+
+	result := &ExecutionResult{Signature: tx.Signatures[0]}
+	failed, err := e.send(ctx, tx, result)
+	if err != nil {
+		return nil, err
+	}
+	if failed {
+		return result, nil
+	}
+	lastSend := time.Now()
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		// The block height is fetched before the status, so a transaction processed right before
+		// the height is observed past lastValidBlockHeight is not reported as expired.
+		height, err := e.rpc.GetBlockHeight(ctx, e.commitment)
+		if err == nil {
+			result.BlockHeight = height
+		}
+
+		statuses, err := e.rpc.GetSignatureStatuses(ctx, false, result.Signature)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		status := statuses[0]
+		switch {
+		case status != nil && status.Err != nil:
+			result.Status, result.Slot, result.Err = ExecutionFailed, status.Slot, status.Err
+			return result, nil
+		case status != nil && reachedCommitment(status, e.commitment):
+			result.Status, result.Slot = ExecutionConfirmed, status.Slot
+			return result, nil
+		case status != nil:
+			// Processed, waiting for the commitment. It cannot expire anymore.
+			continue
+		case result.BlockHeight > lastValidBlockHeight:
+			result.Status = ExecutionExpired
+			return result, nil
+		}
+
+		if time.Since(lastSend) >= e.resendInterval {
+			// Rebroadcast errors are not fatal, the transaction may have been received already.
+			_, _ = e.send(ctx, tx, result)
+			lastSend = time.Now()
+		}
+	}
+}
+
+// send broadcasts the transaction. The preflight simulation only runs for the first send, and failed
+// reports whether it failed, in which case the result is updated with the simulation error.
+func (e *Executor) send(ctx context.Context, tx *solana.Transaction, result *ExecutionResult) (failed bool, err error) {
+	maxRetries := uint64(0) // the executor rebroadcasts the transaction itself
+	opts := &rpc.SendTransactionOptions{
+		SkipPreflight:       !e.preflight || result.Sends > 0,
+		PreflightCommitment: e.commitment,
+		MaxRetries:          &maxRetries,
+	}
+
+	result.Sends++
+	if _, err := e.rpc.SendTransaction(ctx, tx, opts); err != nil {
+		var rpcErr *rpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == rpc.ErrorCodeSendTransactionPreflightFailure {
+			var simulation rpc.SimulationResult
+			if json.Unmarshal(rpcErr.Data, &simulation) == nil && simulation.Err != nil {
+				result.Status, result.Err, result.Logs = ExecutionFailed, simulation.Err, simulation.Logs
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return false, nil
+}
+
+// commitmentLevels orders the commitments.
+var commitmentLevels = map[rpc.Commitment]int{
+	rpc.CommitmentProcessed: 1,
+	rpc.CommitmentConfirmed: 2,
+	rpc.CommitmentFinalized: 3,
+}
+
+// reachedCommitment reports whether the status has reached the given commitment.
+func reachedCommitment(status *rpc.SignatureStatus, commitment rpc.Commitment) bool {
+	reached := status.ConfirmationStatus
+	if reached == "" {
+		// Nodes not reporting the confirmation status only count confirmations until the block is finalized.
+		reached = rpc.CommitmentProcessed
+		if status.Confirmations == nil {
+			reached = rpc.CommitmentFinalized
+		}
+	}
+
+	return commitmentLevels[reached] >= commitmentLevels[commitment]
+}
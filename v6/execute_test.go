@@ -0,0 +1,240 @@
+package v6_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/qiruos/jupiter/solana/rpc/rpctest"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedSwap returns a swap response paid by a new keypair, along with the keypair and the signature of its transaction.
+func signedSwap(t *testing.T) (*v6.SwapResponse, *solana.Keypair, solana.Signature) {
+	t.Helper()
+
+	user, err := solana.NewKeypair()
+	require.NoError(t, err)
+	swap := &v6.SwapResponse{
+		SwapTransaction:      unsignedSwapTransaction(t, user.PublicKey()),
+		LastValidBlockHeight: rpctest.DefaultBlockHeight + rpctest.BlockhashValidity,
+	}
+
+	signed, err := swap.Sign(context.Background(), user)
+	require.NoError(t, err)
+	tx, err := solana.TransactionFromBytes(signed)
+	require.NoError(t, err)
+
+	return swap, user, tx.Signatures[0]
+}
+
+func newExecutor(srv *rpctest.Server, opts ...v6.ExecutorOption) *v6.Executor {
+	return v6.NewExecutor(srv.Client(), append([]v6.ExecutorOption{
+		v6.WithExecutorPollInterval(time.Millisecond),
+		v6.WithExecutorResendInterval(5 * time.Millisecond),
+	}, opts...)...)
+}
+
+func TestExecutor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("confirmed", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, sig := signedSwap(t)
+		srv.SetSignatureStatus(sig, &rpc.SignatureStatus{Slot: 42, ConfirmationStatus: rpc.CommitmentConfirmed})
+
+		result, err := newExecutor(srv).ExecuteSwap(ctx, swap, user)
+		require.NoError(t, err)
+		assert.Equal(t, v6.ExecutionConfirmed, result.Status)
+		assert.Equal(t, sig, result.Signature)
+		assert.EqualValues(t, 42, result.Slot)
+		assert.Nil(t, result.Err)
+		assert.Equal(t, 1, result.Sends)
+
+		var params []json.RawMessage
+		require.NoError(t, srv.Requests(rpctest.MethodSendTransaction)[0].DecodeParams(&params))
+		assert.JSONEq(t, `{"encoding":"base64","skipPreflight":true,"preflightCommitment":"confirmed","maxRetries":0}`, string(params[1]))
+	})
+
+	t.Run("resends until processed", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, sig := signedSwap(t)
+		var polls int32
+		srv.Handle(rpctest.MethodGetSignatureStatuses, func(json.RawMessage) (interface{}, *rpc.Error) {
+			var status *rpc.SignatureStatus
+			switch n := atomic.AddInt32(&polls, 1); {
+			case n > 40:
+				status = &rpc.SignatureStatus{Slot: 43, ConfirmationStatus: rpc.CommitmentFinalized}
+			case n > 20:
+				status = &rpc.SignatureStatus{Slot: 43, ConfirmationStatus: rpc.CommitmentProcessed}
+			}
+			return map[string]interface{}{"context": rpc.Context{Slot: 1}, "value": []*rpc.SignatureStatus{status}}, nil
+		})
+
+		result, err := newExecutor(srv, v6.WithExecutorCommitment(rpc.CommitmentFinalized)).ExecuteSwap(ctx, swap, user)
+		require.NoError(t, err)
+		assert.Equal(t, v6.ExecutionConfirmed, result.Status)
+		assert.Greater(t, result.Sends, 1)
+		assert.Len(t, srv.Transactions(), result.Sends)
+		for _, tx := range srv.Transactions() {
+			assert.Equal(t, sig, tx.Signatures[0])
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, sig := signedSwap(t)
+		srv.SetSignatureStatus(sig, &rpc.SignatureStatus{
+			Slot:               44,
+			Err:                &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[3,{"Custom":6001}]}`)},
+			ConfirmationStatus: rpc.CommitmentProcessed,
+		})
+
+		result, err := newExecutor(srv).ExecuteSwap(ctx, swap, user)
+		require.NoError(t, err)
+		assert.Equal(t, v6.ExecutionFailed, result.Status)
+		assert.EqualValues(t, 44, result.Slot)
+		require.NotNil(t, result.Err)
+		require.NotNil(t, result.Err.Custom)
+		assert.EqualValues(t, 6001, *result.Err.Custom)
+	})
+
+	t.Run("preflight failure", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, _ := signedSwap(t)
+		srv.Handle(rpctest.MethodSendTransaction, func(json.RawMessage) (interface{}, *rpc.Error) {
+			return nil, &rpc.Error{
+				Code:    rpc.ErrorCodeSendTransactionPreflightFailure,
+				Message: "Transaction simulation failed: Error processing Instruction 3: custom program error: 0x1771",
+				Data:    json.RawMessage(`{"err":{"InstructionError":[3,{"Custom":6001}]},"logs":["Program log: Error: SlippageToleranceExceeded"]}`),
+			}
+		})
+
+		result, err := newExecutor(srv, v6.WithExecutorPreflight(true)).ExecuteSwap(ctx, swap, user)
+		require.NoError(t, err)
+		assert.Equal(t, v6.ExecutionFailed, result.Status)
+		require.NotNil(t, result.Err)
+		assert.Equal(t, 3, result.Err.InstructionIndex)
+		assert.Equal(t, []string{"Program log: Error: SlippageToleranceExceeded"}, result.Logs)
+		assert.Empty(t, srv.Requests(rpctest.MethodGetSignatureStatuses))
+	})
+
+	t.Run("send error", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, _ := signedSwap(t)
+		srv.Handle(rpctest.MethodSendTransaction, func(json.RawMessage) (interface{}, *rpc.Error) {
+			return nil, &rpc.Error{Code: rpc.ErrorCodeNodeUnhealthy, Message: "Node is unhealthy"}
+		})
+
+		_, err := newExecutor(srv).ExecuteSwap(ctx, swap, user)
+		var rpcErr *rpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, rpc.ErrorCodeNodeUnhealthy, rpcErr.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, _ := signedSwap(t)
+		srv.SetBlockHeight(uint64(swap.LastValidBlockHeight) + 1)
+
+		result, err := newExecutor(srv).ExecuteSwap(ctx, swap, user)
+		require.NoError(t, err)
+		assert.Equal(t, v6.ExecutionExpired, result.Status)
+		assert.EqualValues(t, swap.LastValidBlockHeight+1, result.BlockHeight)
+		assert.Zero(t, result.Slot)
+	})
+
+	t.Run("processed does not expire", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, sig := signedSwap(t)
+		srv.SetBlockHeight(uint64(swap.LastValidBlockHeight) + 1)
+		srv.SetSignatureStatus(sig, &rpc.SignatureStatus{Slot: 45, ConfirmationStatus: rpc.CommitmentProcessed})
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := newExecutor(srv).ExecuteSwap(ctx, swap, user)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	for _, commitment := range []rpc.Commitment{"", "recent"} {
+		t.Run(fmt.Sprintf("commitment %q defaults to confirmed", commitment), func(t *testing.T) {
+			srv := rpctest.NewServer()
+			defer srv.Close()
+
+			swap, user, sig := signedSwap(t)
+			srv.SetSignatureStatus(sig, &rpc.SignatureStatus{Slot: 46, ConfirmationStatus: rpc.CommitmentProcessed})
+
+			// A processed transaction is not confirmed yet.
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			_, err := newExecutor(srv, v6.WithExecutorCommitment(commitment)).ExecuteSwap(ctx, swap, user)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+			var params []json.RawMessage
+			require.NoError(t, srv.Requests(rpctest.MethodGetBlockHeight)[0].DecodeParams(&params))
+			assert.JSONEq(t, `{"commitment":"confirmed"}`, string(params[0]))
+		})
+	}
+
+	t.Run("non-positive intervals default", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, sig := signedSwap(t)
+		srv.SetSignatureStatus(sig, &rpc.SignatureStatus{Slot: 47, ConfirmationStatus: rpc.CommitmentConfirmed})
+
+		var result *v6.ExecutionResult
+		var err error
+		executor := v6.NewExecutor(srv.Client(), v6.WithExecutorPollInterval(0), v6.WithExecutorResendInterval(-time.Second))
+		require.NotPanics(t, func() { result, err = executor.ExecuteSwap(ctx, swap, user) })
+		require.NoError(t, err)
+		assert.Equal(t, v6.ExecutionConfirmed, result.Status)
+		assert.Equal(t, 1, result.Sends)
+	})
+
+	t.Run("missing last valid block height", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, user, _ := signedSwap(t)
+		swap.LastValidBlockHeight = 0
+
+		_, err := newExecutor(srv).ExecuteSwap(ctx, swap, user)
+		assert.EqualError(t, err, "lastValidBlockHeight is required")
+		assert.Empty(t, srv.Transactions())
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		srv := rpctest.NewServer()
+		defer srv.Close()
+
+		swap, _, _ := signedSwap(t)
+		tx, err := swap.Transaction()
+		require.NoError(t, err)
+
+		_, err = newExecutor(srv).Execute(ctx, tx, uint64(swap.LastValidBlockHeight))
+		assert.ErrorContains(t, err, "transaction is not signed")
+		assert.Empty(t, srv.Transactions())
+	})
+}
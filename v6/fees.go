@@ -0,0 +1,219 @@
+package v6
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// PriorityLevel is a priority level Jupiter estimates the prioritization fee for.
+type PriorityLevel string
+
+// Predefined priority levels.
+const (
+	PriorityLevelMedium   PriorityLevel = "medium"
+	PriorityLevelHigh     PriorityLevel = "high"
+	PriorityLevelVeryHigh PriorityLevel = "veryHigh"
+)
+
+// PrioritizationFeeKind identifies the variant of a PrioritizationFee.
+type PrioritizationFeeKind int
+
+// Prioritization fee variants.
+const (
+	PrioritizationFeeKindLamports       PrioritizationFeeKind = iota + 1 // fixed amount of lamports
+	PrioritizationFeeKindAuto                                            // "auto"
+	PrioritizationFeeKindAutoMultiplier                                  // {"autoMultiplier": n}
+	PrioritizationFeeKindJitoTip                                         // {"jitoTipLamports": n}
+	PrioritizationFeeKindPriorityLevel                                   // {"priorityLevelWithMaxLamports": {...}}
+)
+
+const auto = "auto"
+
+// PrioritizationFee is the prioritization fee of a swap, see SwapParams.PrioritizationFeeLamports.
+// Use one of the PrioritizationFee* constructors to create one.
+type PrioritizationFee struct {
+	kind          PrioritizationFeeKind
+	lamports      uint64 // fixed fee, Jito tip or max lamports for a priority level
+	multiplier    uint64
+	priorityLevel PriorityLevel
+}
+
+// PrioritizationFeeFixed returns a prioritization fee of the given amount of lamports.
+func PrioritizationFeeFixed(lamports uint64) *PrioritizationFee {
+	return &PrioritizationFee{kind: PrioritizationFeeKindLamports, lamports: lamports}
+}
+
+// PrioritizationFeeAuto returns a prioritization fee set automatically by Jupiter,
+// capped at 5,000,000 lamports / 0.005 SOL.
+func PrioritizationFeeAuto() *PrioritizationFee {
+	return &PrioritizationFee{kind: PrioritizationFeeKindAuto}
+}
+
+// PrioritizationFeeAutoMultiplier returns a prioritization fee of multiplier times the fee Jupiter would set automatically.
+func PrioritizationFeeAutoMultiplier(multiplier uint64) *PrioritizationFee {
+	return &PrioritizationFee{kind: PrioritizationFeeKindAutoMultiplier, multiplier: multiplier}
+}
+
+// PrioritizationFeeJitoTip returns a prioritization fee paid as a Jito tip of the given amount of lamports.
+// A tip instruction is included in the transaction and no priority fee is set.
+func PrioritizationFeeJitoTip(lamports uint64) *PrioritizationFee {
+	return &PrioritizationFee{kind: PrioritizationFeeKindJitoTip, lamports: lamports}
+}
+
+// PrioritizationFeeWithPriorityLevel returns a prioritization fee estimated by Jupiter for the given priority level,
+// capped at maxLamports.
+func PrioritizationFeeWithPriorityLevel(level PriorityLevel, maxLamports uint64) *PrioritizationFee {
+	return &PrioritizationFee{kind: PrioritizationFeeKindPriorityLevel, priorityLevel: level, lamports: maxLamports}
+}
+
+// Kind returns the variant of the fee.
+func (f PrioritizationFee) Kind() PrioritizationFeeKind {
+	return f.kind
+}
+
+// Lamports returns the fixed fee, the Jito tip or the max lamports of a priority level, depending on the variant.
+func (f PrioritizationFee) Lamports() uint64 {
+	return f.lamports
+}
+
+// Multiplier returns the multiplier of an auto multiplier fee.
+func (f PrioritizationFee) Multiplier() uint64 {
+	return f.multiplier
+}
+
+// PriorityLevel returns the priority level of a priority level fee.
+func (f PrioritizationFee) PriorityLevel() PriorityLevel {
+	return f.priorityLevel
+}
+
+type (
+	prioritizationFeeObject struct {
+		AutoMultiplier               *uint64                       `json:"autoMultiplier,omitempty"`
+		JitoTipLamports              *uint64                       `json:"jitoTipLamports,omitempty"`
+		PriorityLevelWithMaxLamports *priorityLevelWithMaxLamports `json:"priorityLevelWithMaxLamports,omitempty"`
+	}
+
+	priorityLevelWithMaxLamports struct {
+		PriorityLevel PriorityLevel `json:"priorityLevel"`
+		MaxLamports   uint64        `json:"maxLamports"`
+	}
+)
+
+// MarshalJSON implements json.Marshaler.
+func (f PrioritizationFee) MarshalJSON() ([]byte, error) {
+	switch f.kind {
+	case PrioritizationFeeKindLamports:
+		return []byte(strconv.FormatUint(f.lamports, 10)), nil
+	case PrioritizationFeeKindAuto:
+		return json.Marshal(auto)
+	case PrioritizationFeeKindAutoMultiplier:
+		return json.Marshal(prioritizationFeeObject{AutoMultiplier: &f.multiplier})
+	case PrioritizationFeeKindJitoTip:
+		return json.Marshal(prioritizationFeeObject{JitoTipLamports: &f.lamports})
+	case PrioritizationFeeKindPriorityLevel:
+		return json.Marshal(prioritizationFeeObject{PriorityLevelWithMaxLamports: &priorityLevelWithMaxLamports{
+			PriorityLevel: f.priorityLevel,
+			MaxLamports:   f.lamports,
+		}})
+	}
+
+	return nil, fmt.Errorf("invalid prioritization fee kind: %d", f.kind)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *PrioritizationFee) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s != auto {
+			return fmt.Errorf("invalid prioritization fee %q", s)
+		}
+		*f = *PrioritizationFeeAuto()
+	case len(data) > 0 && data[0] == '{':
+		var obj prioritizationFeeObject
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		switch {
+		case obj.AutoMultiplier != nil:
+			*f = *PrioritizationFeeAutoMultiplier(*obj.AutoMultiplier)
+		case obj.JitoTipLamports != nil:
+			*f = *PrioritizationFeeJitoTip(*obj.JitoTipLamports)
+		case obj.PriorityLevelWithMaxLamports != nil:
+			*f = *PrioritizationFeeWithPriorityLevel(obj.PriorityLevelWithMaxLamports.PriorityLevel, obj.PriorityLevelWithMaxLamports.MaxLamports)
+		default:
+			return fmt.Errorf("invalid prioritization fee %s", data)
+		}
+	default:
+		var lamports uint64
+		if err := json.Unmarshal(data, &lamports); err != nil {
+			return fmt.Errorf("invalid prioritization fee %s: %w", data, err)
+		}
+		*f = *PrioritizationFeeFixed(lamports)
+	}
+
+	return nil
+}
+
+// ComputeUnitPrice is the compute unit price of a swap, see SwapParams.ComputeUnitPriceMicroLamports.
+// Use ComputeUnitPriceFixed or ComputeUnitPriceAuto to create one.
+type ComputeUnitPrice struct {
+	auto          bool
+	microLamports uint64
+}
+
+// ComputeUnitPriceFixed returns a compute unit price of the given amount of micro lamports.
+func ComputeUnitPriceFixed(microLamports uint64) *ComputeUnitPrice {
+	return &ComputeUnitPrice{microLamports: microLamports}
+}
+
+// ComputeUnitPriceAuto returns a compute unit price set automatically by Jupiter.
+func ComputeUnitPriceAuto() *ComputeUnitPrice {
+	return &ComputeUnitPrice{auto: true}
+}
+
+// IsAuto reports whether the price is set automatically by Jupiter.
+func (p ComputeUnitPrice) IsAuto() bool {
+	return p.auto
+}
+
+// MicroLamports returns the fixed price in micro lamports, 0 if the price is set automatically.
+func (p ComputeUnitPrice) MicroLamports() uint64 {
+	return p.microLamports
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p ComputeUnitPrice) MarshalJSON() ([]byte, error) {
+	if p.auto {
+		return json.Marshal(auto)
+	}
+
+	return []byte(strconv.FormatUint(p.microLamports, 10)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *ComputeUnitPrice) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != auto {
+			return fmt.Errorf("invalid compute unit price %q", s)
+		}
+		*p = *ComputeUnitPriceAuto()
+		return nil
+	}
+
+	var microLamports uint64
+	if err := json.Unmarshal(data, &microLamports); err != nil {
+		return fmt.Errorf("invalid compute unit price %s: %w", data, err)
+	}
+	*p = *ComputeUnitPriceFixed(microLamports)
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package v6_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrioritizationFeeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		fee  *v6.PrioritizationFee
+		want string
+	}{
+		{name: "lamports", fee: v6.PrioritizationFeeFixed(5000), want: `5000`},
+		{name: "auto", fee: v6.PrioritizationFeeAuto(), want: `"auto"`},
+		{name: "auto multiplier", fee: v6.PrioritizationFeeAutoMultiplier(3), want: `{"autoMultiplier":3}`},
+		{name: "jito tip", fee: v6.PrioritizationFeeJitoTip(10000), want: `{"jitoTipLamports":10000}`},
+		{
+			name: "priority level",
+			fee:  v6.PrioritizationFeeWithPriorityLevel(v6.PriorityLevelVeryHigh, 4000000),
+			want: `{"priorityLevelWithMaxLamports":{"priorityLevel":"veryHigh","maxLamports":4000000}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := json.Marshal(tt.fee)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(buf))
+
+			var decoded v6.PrioritizationFee
+			require.NoError(t, json.Unmarshal(buf, &decoded))
+			assert.Equal(t, *tt.fee, decoded)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var fee v6.PrioritizationFee
+		assert.Error(t, json.Unmarshal([]byte(`"manual"`), &fee))
+		assert.Error(t, json.Unmarshal([]byte(`{}`), &fee))
+		assert.Error(t, json.Unmarshal([]byte(`-1`), &fee))
+
+		_, err := json.Marshal(v6.PrioritizationFee{})
+		assert.Error(t, err)
+	})
+}
+
+func TestComputeUnitPriceJSON(t *testing.T) {
+	for _, tt := range []struct {
+		price *v6.ComputeUnitPrice
+		want  string
+	}{
+		{price: v6.ComputeUnitPriceFixed(1000), want: `1000`},
+		{price: v6.ComputeUnitPriceAuto(), want: `"auto"`},
+	} {
+		buf, err := json.Marshal(tt.price)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, string(buf))
+
+		var decoded v6.ComputeUnitPrice
+		require.NoError(t, json.Unmarshal(buf, &decoded))
+		assert.Equal(t, *tt.price, decoded)
+	}
+}
+
+func TestSwapPrioritizationFee(t *testing.T) {
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	_, err := c.Swap(v6.SwapParams{
+		QuoteResponse:             &v6.QuoteResponse{},
+		UserPublicKey:             userPublicKey,
+		PrioritizationFeeLamports: v6.PrioritizationFeeJitoTip(5000),
+	})
+	require.NoError(t, err)
+
+	req, ok := srv.LastRequest(jupitertest.EndpointSwap)
+	require.True(t, ok)
+
+	var body map[string]json.RawMessage
+	require.NoError(t, req.DecodeJSON(&body))
+	assert.JSONEq(t, `{"jitoTipLamports":5000}`, string(body["prioritizationFeeLamports"]))
+	assert.NotContains(t, body, "computeUnitPriceMicroLamports")
+
+	params, err := req.SwapParams()
+	require.NoError(t, err)
+	require.NotNil(t, params.PrioritizationFeeLamports)
+	assert.Equal(t, v6.PrioritizationFeeKindJitoTip, params.PrioritizationFeeLamports.Kind())
+	assert.EqualValues(t, 5000, params.PrioritizationFeeLamports.Lamports())
+}
@@ -0,0 +1,122 @@
+package v6
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HeaderAPIKey is the header used to authenticate against the paid Jupiter API tiers.
+	HeaderAPIKey = "x-api-key"
+
+	redacted = "[REDACTED]"
+)
+
+// HeaderFunc is called for every request to mutate its headers before it is sent.
+// Returning an error aborts the request.
+type HeaderFunc func(ctx context.Context, op Operation, header http.Header) error
+
+// setHeaders applies the static headers, the API key, the headers set by middlewares on the call
+// and the header hook to the request headers.
+func (c *Client) setHeaders(ctx context.Context, call *Call, header http.Header) error {
+	for k, v := range c.headers {
+		header[k] = append([]string(nil), v...)
+	}
+
+	if c.apiKey != "" {
+		header.Set(HeaderAPIKey, c.apiKey)
+	}
+
+	for k, v := range call.Header {
+		header[k] = append([]string(nil), v...)
+	}
+
+	if c.headerFunc != nil {
+		return c.headerFunc(ctx, call.Operation, header)
+	}
+
+	return nil
+}
+
+// isSensitiveHeader reports whether the header carries credentials.
+func isSensitiveHeader(key string) bool {
+	switch http.CanonicalHeaderKey(key) {
+	case http.CanonicalHeaderKey(HeaderAPIKey), "Authorization", "Proxy-Authorization", "Cookie":
+		return true
+	}
+
+	return false
+}
+
+// addSecret registers a value to be redacted from errors.
+func (c *Client) addSecret(secret string) {
+	if secret != "" {
+		c.secrets = append(c.secrets, secret)
+	}
+}
+
+// redact replaces in s the registered secrets and the values of the sensitive headers of the request,
+// so credentials set by a HeaderFunc or a middleware are redacted too.
+func (c *Client) redact(s string, header http.Header) string {
+	for _, secret := range c.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	for _, secret := range sensitiveValues(header) {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+
+	return s
+}
+
+// sensitiveValues returns the values of the sensitive headers, along with their credentials
+// without the authentication scheme, e.g. the token of "Bearer <token>".
+func sensitiveValues(header http.Header) []string {
+	var values []string
+	for k, vv := range header {
+		if !isSensitiveHeader(k) {
+			continue
+		}
+		for _, v := range vv {
+			if v == "" {
+				continue
+			}
+			values = append(values, v)
+			if i := strings.IndexByte(v, ' '); i > 0 && strings.TrimSpace(v[i:]) != "" {
+				values = append(values, strings.TrimSpace(v[i:]))
+			}
+		}
+	}
+
+	return values
+}
+
+// redactError wraps err so that its message does not contain any known secret
+// nor the value of a sensitive header of the request.
+func (c *Client) redactError(err error, header http.Header) error {
+	if err == nil {
+		return err
+	}
+
+	msg := err.Error()
+	if r := c.redact(msg, header); r != msg {
+		return &redactedError{msg: r, err: err}
+	}
+
+	return err
+}
+
+// redactedError is an error whose message has secrets removed.
+// The original error is still available through errors.Is and errors.As.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,130 @@
+package v6_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaders(t *testing.T) {
+	const apiKey = "secret-api-key"
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		if r.Header.Get(v6.HeaderAPIKey) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"missing api key"}`))
+			return
+		}
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"invalid api key ` + r.Header.Get(v6.HeaderAPIKey) + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"swapTransaction":"tx"}`))
+	}))
+	defer srv.Close()
+
+	var ops []v6.Operation
+	newClient := func(opts ...v6.ClientOption) *v6.Client {
+		return v6.NewClient(append([]v6.ClientOption{
+			v6.WithAPIURL(srv.URL),
+			v6.WithAPIKey(apiKey),
+			v6.WithHeader("X-Gateway", "gw-1"),
+			v6.WithHeaderFunc(func(ctx context.Context, op v6.Operation, header http.Header) error {
+				ops = append(ops, op)
+				header.Set("X-Operation", string(op))
+				return nil
+			}),
+		}, opts...)...)
+	}
+
+	t.Run("applied to get and post", func(t *testing.T) {
+		c := newClient()
+
+		_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		require.NoError(t, err)
+		assert.Equal(t, apiKey, got.Get(v6.HeaderAPIKey))
+		assert.Equal(t, "gw-1", got.Get("X-Gateway"))
+		assert.Equal(t, "Quote", got.Get("X-Operation"))
+
+		_, err = c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		assert.Equal(t, apiKey, got.Get(v6.HeaderAPIKey))
+		assert.Equal(t, "gw-1", got.Get("X-Gateway"))
+		assert.Equal(t, "Swap", got.Get("X-Operation"))
+		assert.Equal(t, v6.ContentTypeJSON, got.Get("Content-Type"))
+
+		assert.Equal(t, []v6.Operation{v6.OperationQuote, v6.OperationSwap}, ops)
+	})
+
+	t.Run("api key is redacted from errors", func(t *testing.T) {
+		c := newClient(v6.WithHeader("X-Fail", "1"))
+
+		_, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), apiKey)
+		assert.Contains(t, err.Error(), "[REDACTED]")
+
+		var apiErr *v6.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.NotContains(t, string(apiErr.Body), apiKey)
+	})
+
+	t.Run("dynamic credentials are redacted from errors", func(t *testing.T) {
+		const token = "dynamic-bearer-token"
+		const key = "middleware-api-key"
+
+		c := newClient(
+			v6.WithHeader("X-Fail", "1"),
+			v6.WithHeaderFunc(func(ctx context.Context, op v6.Operation, header http.Header) error {
+				header.Set("Authorization", "Bearer "+token)
+				return nil
+			}),
+			v6.WithMiddleware(func(next v6.Handler) v6.Handler {
+				return func(ctx context.Context, call *v6.Call) (interface{}, error) {
+					call.Header.Set(v6.HeaderAPIKey, key)
+					return next(ctx, call)
+				}
+			}),
+		)
+
+		_, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.Error(t, err)
+		assert.Equal(t, "Bearer "+token, got.Get("Authorization"))
+		assert.NotContains(t, err.Error(), key)
+		assert.Contains(t, err.Error(), "[REDACTED]")
+
+		var apiErr *v6.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.NotContains(t, string(apiErr.Body), key)
+
+		c = newClient(v6.WithHeaderFunc(func(ctx context.Context, op v6.Operation, header http.Header) error {
+			header.Set("Authorization", "Bearer "+token)
+			return errors.New("token " + token + " expired")
+		}))
+		_, err = c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), token)
+		assert.Contains(t, err.Error(), "token [REDACTED] expired")
+	})
+
+	t.Run("hook error aborts request", func(t *testing.T) {
+		hookErr := errors.New("signing failed")
+		c := newClient(v6.WithHeaderFunc(func(ctx context.Context, op v6.Operation, header http.Header) error {
+			return hookErr
+		}))
+
+		got = nil
+		_, err := c.SwapInstructions(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.ErrorIs(t, err, hookErr)
+		assert.Nil(t, got)
+	})
+}
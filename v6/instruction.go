@@ -0,0 +1,50 @@
+package v6
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/qiruos/jupiter/solana"
+)
+
+// DataBytes returns the decoded instruction data.
+func (i Instruction) DataBytes() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(i.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instruction data: %w", err)
+	}
+	return data, nil
+}
+
+// Decode returns the typed instruction, such as *solana.SetComputeUnitPrice or
+// *solana.CreateAssociatedTokenAccount, using solana.DefaultDecoderRegistry.
+// Instructions of unknown programs fail with an error wrapping solana.ErrUnknownProgram.
+func (i Instruction) Decode() (solana.DecodedInstruction, error) {
+	ix, err := i.SolanaInstruction()
+	if err != nil {
+		return nil, err
+	}
+	return solana.DecodeInstruction(ix)
+}
+
+// Signers returns the accounts that must sign the instruction.
+func (i Instruction) Signers() []solana.PublicKey {
+	var signers []solana.PublicKey
+	for _, a := range i.Accounts {
+		if a.IsSigner {
+			signers = append(signers, a.Pubkey)
+		}
+	}
+	return signers
+}
+
+// WritableAccounts returns the accounts written by the instruction.
+func (i Instruction) WritableAccounts() []solana.PublicKey {
+	var writable []solana.PublicKey
+	for _, a := range i.Accounts {
+		if a.IsWritable {
+			writable = append(writable, a.Pubkey)
+		}
+	}
+	return writable
+}
@@ -0,0 +1,82 @@
+package v6_test
+
+import (
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/utils"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstructionDecode(t *testing.T) {
+	c := cassetteClient(t, "swap_instructions")
+	quoteResponse, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+	require.NoError(t, err)
+	instructions, err := c.SwapInstructions(v6.SwapParams{
+		UserPublicKey:    userPublicKey,
+		QuoteResponse:    quoteResponse,
+		WrapAndUnwrapSol: utils.Pointer(true),
+	})
+	require.NoError(t, err)
+
+	wSolATA := solana.MustPublicKeyFromBase58("7Jbm4XGnLPZ6qe4JRQEZ5fiEikdrPThXGJzXDb4oX6yn")
+
+	t.Run("compute budget", func(t *testing.T) {
+		require.Len(t, instructions.ComputeBudgetInstructions, 1)
+		decoded, err := instructions.ComputeBudgetInstructions[0].Decode()
+		require.NoError(t, err)
+		assert.Equal(t, &solana.SetComputeUnitLimit{Units: 1400000}, decoded)
+	})
+
+	t.Run("setup", func(t *testing.T) {
+		require.Len(t, instructions.SetupInstructions, 1)
+		decoded, err := instructions.SetupInstructions[0].Decode()
+		require.NoError(t, err)
+		assert.Equal(t, &solana.CreateAssociatedTokenAccount{
+			Payer:             userPublicKey,
+			AssociatedAccount: wSolATA,
+			Wallet:            userPublicKey,
+			Mint:              wSolMint,
+			TokenProgram:      solana.TokenProgramID,
+			Idempotent:        true,
+		}, decoded)
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		decoded, err := instructions.SwapInstruction.Decode()
+		require.NoError(t, err)
+		route, ok := decoded.(*solana.JupiterRoute)
+		require.True(t, ok, "unexpected type %T", decoded)
+		assert.Equal(t, "Route", route.Name())
+		assert.EqualValues(t, quoteResponse.InAmount, route.InAmount)
+		assert.EqualValues(t, quoteResponse.SlippageBps, route.SlippageBps)
+		assert.Equal(t, []solana.PublicKey{userPublicKey}, instructions.SwapInstruction.Signers())
+	})
+
+	t.Run("cleanup", func(t *testing.T) {
+		require.NotNil(t, instructions.CleanupInstruction)
+		decoded, err := instructions.CleanupInstruction.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, &solana.TokenCloseAccount{
+			Program:     solana.TokenProgramID,
+			Account:     wSolATA,
+			Destination: userPublicKey,
+			Owner:       userPublicKey,
+		}, decoded)
+		assert.Equal(t, []solana.PublicKey{wSolATA, userPublicKey}, instructions.CleanupInstruction.WritableAccounts())
+	})
+}
+
+func TestInstructionDataBytes(t *testing.T) {
+	data, err := v6.Instruction{Data: "AsBcFQA="}.DataBytes()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 0xc0, 0x5c, 0x15, 0}, data)
+
+	_, err = v6.Instruction{Data: "not base64"}.DataBytes()
+	assert.ErrorContains(t, err, "invalid instruction data")
+
+	_, err = v6.Instruction{ProgramId: solana.ComputeBudgetProgramID, Data: "not base64"}.Decode()
+	assert.ErrorContains(t, err, "invalid instruction data")
+}
@@ -0,0 +1,431 @@
+// Package jupitertest provides an in-process fake of the Jupiter v6 API for tests.
+//
+// The fake serves /quote, /swap and /swap-instructions with sensible defaults,
+// and lets tests script responses, inject errors and latency, and inspect the
+// requests received:
+//
+//	srv := jupitertest.NewServer()
+//	defer srv.Close()
+//
+//	srv.InjectFault(jupitertest.EndpointQuote, jupitertest.Fault{
+//		StatusCode: http.StatusBadRequest,
+//		ErrorCode:  v6.ErrorCodeCouldNotFindAnyRoute,
+//	})
+//
+//	c := srv.Client()
+//	_, err := c.Quote(params) // errors.Is(err, v6.ErrNoRoute)
+package jupitertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qiruos/jupiter/v6"
+)
+
+// Endpoints served by the fake.
+const (
+	EndpointQuote            = "/quote"
+	EndpointSwap             = "/swap"
+	EndpointSwapInstructions = "/swap-instructions"
+)
+
+// DefaultComputedAutoSlippage is the slippage in BPS computed by /quote when autoSlippage is requested.
+const DefaultComputedAutoSlippage = 75
+
+// DefaultLastValidBlockHeight is the last valid block height returned by /swap unless scripted otherwise.
+const DefaultLastValidBlockHeight = 1000
+
+// DefaultComputeUnitLimit is the compute unit limit returned by /swap unless scripted otherwise.
+const DefaultComputeUnitLimit = 200000
+
+// DefaultSwapTransaction is the base64 encoded transaction returned by /swap unless scripted otherwise.
+const DefaultSwapTransaction = "AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACAAQACA2xYmFyB93Q3vWBVmo7awYmV6WW4Hhb2NFTerAmRaTDvAwZGb+UhFzL/7K26csOb57yM5bvF9xJrLEObOkAAAAAEedVb8jHAbu50xW7OaBUH/bGy3qP0jlECsc2iVrwTj8xJDpKM0uOHO7ND/JXaMxecpg9Nv0bCw26RKZ1V1Oa5AwEABQLAXBUAAQAJA+gDAAAAAAAAAgQAAwQFCOUXy5d6460qARmPH0w6RSJj1BOyzRfry8Gg5YhzZOYmGhKoF5LqFlo+AgABAQI="
+
+type (
+	// Server is a fake Jupiter API server backed by httptest.Server.
+	Server struct {
+		URL string // base URL of the fake, to be used with v6.WithAPIURL
+
+		srv *httptest.Server
+
+		mu        sync.Mutex
+		responses map[string]interface{}
+		handlers  map[string]http.HandlerFunc
+		faults    map[string][]Fault
+		latency   map[string]time.Duration
+		requests  []Request
+	}
+
+	// Fault describes an error response injected by the fake.
+	Fault struct {
+		StatusCode int         // HTTP status code, default is 500
+		ErrorCode  string      // Jupiter error code, e.g. "COULD_NOT_FIND_ANY_ROUTE"
+		Message    string      // Jupiter error message
+		Header     http.Header // extra response headers, e.g. Retry-After
+		Body       string      // raw response body, overrides ErrorCode and Message if set
+	}
+
+	// Request is a request received by the fake.
+	Request struct {
+		Method   string
+		Endpoint string
+		Query    url.Values
+		Header   http.Header
+		Body     []byte
+	}
+)
+
+// NewServer starts and returns a new fake Jupiter API server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		responses: make(map[string]interface{}),
+		handlers:  make(map[string]http.HandlerFunc),
+		faults:    make(map[string][]Fault),
+		latency:   make(map[string]time.Duration),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a v6.Client talking to the fake, configured with the given additional options.
+func (s *Server) Client(opts ...v6.ClientOption) *v6.Client {
+	return v6.NewClient(append([]v6.ClientOption{
+		v6.WithHTTPClient(s.srv.Client()),
+		v6.WithAPIURL(s.URL),
+	}, opts...)...)
+}
+
+// SetQuoteResponse makes /quote respond with the given quote instead of the default one.
+func (s *Server) SetQuoteResponse(resp *v6.QuoteResponse) {
+	s.setResponse(EndpointQuote, resp)
+}
+
+// SetSwapResponse makes /swap respond with the given response instead of the default one.
+func (s *Server) SetSwapResponse(resp *v6.SwapResponse) {
+	s.setResponse(EndpointSwap, resp)
+}
+
+// SetSwapInstructionsResponse makes /swap-instructions respond with the given response instead of the default one.
+func (s *Server) SetSwapInstructionsResponse(resp *v6.SwapInstructionsResp) {
+	s.setResponse(EndpointSwapInstructions, resp)
+}
+
+// Handle replaces the handler of the given endpoint. Faults and latency still apply.
+func (s *Server) Handle(endpoint string, h http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[endpoint] = h
+}
+
+// InjectFault makes the next request to the given endpoint fail with the given fault.
+// Faults are queued, so injecting several makes as many consecutive requests fail.
+func (s *Server) InjectFault(endpoint string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[endpoint] = append(s.faults[endpoint], f)
+}
+
+// SetLatency delays every response of the given endpoint by d.
+func (s *Server) SetLatency(endpoint string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency[endpoint] = d
+}
+
+// Requests returns the requests received on the given endpoint, or all requests if endpoint is empty.
+func (s *Server) Requests(endpoint string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reqs []Request
+	for _, r := range s.requests {
+		if endpoint == "" || r.Endpoint == endpoint {
+			reqs = append(reqs, r)
+		}
+	}
+
+	return reqs
+}
+
+// LastRequest returns the last request received on the given endpoint, or false if there is none.
+func (s *Server) LastRequest(endpoint string) (Request, bool) {
+	reqs := s.Requests(endpoint)
+	if len(reqs) == 0 {
+		return Request{}, false
+	}
+
+	return reqs[len(reqs)-1], true
+}
+
+// Reset clears scripted responses, handlers, faults, latency and recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = make(map[string]interface{})
+	s.handlers = make(map[string]http.HandlerFunc)
+	s.faults = make(map[string][]Fault)
+	s.latency = make(map[string]time.Duration)
+	s.requests = nil
+}
+
+// DecodeJSON decodes the JSON body of the request into v.
+func (r Request) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// SwapParams decodes the body of a /swap or /swap-instructions request.
+func (r Request) SwapParams() (v6.SwapParams, error) {
+	var params v6.SwapParams
+	err := r.DecodeJSON(&params)
+
+	return params, err
+}
+
+func (s *Server) setResponse(endpoint string, resp interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[endpoint] = resp
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method:   r.Method,
+		Endpoint: r.URL.Path,
+		Query:    r.URL.Query(),
+		Header:   r.Header.Clone(),
+		Body:     body,
+	})
+	latency := s.latency[r.URL.Path]
+	var fault *Fault
+	if faults := s.faults[r.URL.Path]; len(faults) > 0 {
+		fault = &faults[0]
+		s.faults[r.URL.Path] = faults[1:]
+	}
+	handler := s.handlers[r.URL.Path]
+	resp, scripted := s.responses[r.URL.Path]
+	s.mu.Unlock()
+
+	if latency > 0 && !sleep(r.Context(), latency) {
+		return
+	}
+
+	if fault != nil {
+		writeFault(w, fault)
+		return
+	}
+
+	if handler != nil {
+		handler(w, r)
+		return
+	}
+
+	if scripted {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	switch r.URL.Path {
+	case EndpointQuote:
+		if r.Method != http.MethodGet {
+			writeFault(w, &Fault{StatusCode: http.StatusMethodNotAllowed})
+			return
+		}
+		writeJSON(w, http.StatusOK, defaultQuote(r.URL.Query()))
+	case EndpointSwap:
+		if r.Method != http.MethodPost {
+			writeFault(w, &Fault{StatusCode: http.StatusMethodNotAllowed})
+			return
+		}
+		writeJSON(w, http.StatusOK, defaultSwap(body))
+	case EndpointSwapInstructions:
+		if r.Method != http.MethodPost {
+			writeFault(w, &Fault{StatusCode: http.StatusMethodNotAllowed})
+			return
+		}
+		writeJSON(w, http.StatusOK, defaultSwapInstructions())
+	default:
+		writeFault(w, &Fault{StatusCode: http.StatusNotFound, Message: "Route not found"})
+	}
+}
+
+// defaultQuote builds a 1:1 single-hop quote echoing the request parameters.
+func defaultQuote(q url.Values) map[string]interface{} {
+	swapMode := q.Get("swapMode")
+	if swapMode == "" {
+		swapMode = v6.SwapModeExactIn
+	}
+
+	slippageBps, err := strconv.ParseUint(q.Get("slippageBps"), 10, 64)
+	if err != nil {
+		slippageBps = 50
+	}
+
+	var computedAutoSlippage uint64
+	if q.Get("autoSlippage") == "true" {
+		computedAutoSlippage = DefaultComputedAutoSlippage
+		slippageBps = computedAutoSlippage
+		if maxBps, err := strconv.ParseUint(q.Get("maxAutoSlippageBps"), 10, 64); err == nil && maxBps < slippageBps {
+			slippageBps = maxBps
+		}
+	}
+
+	amount, _ := strconv.ParseUint(q.Get("amount"), 10, 64)
+	threshold := amount - amount*slippageBps/10000
+	if swapMode == v6.SwapModeExactOut {
+		threshold = amount + amount*slippageBps/10000
+	}
+
+	quote := map[string]interface{}{
+		"inputMint":            q.Get("inputMint"),
+		"inAmount":             strconv.FormatUint(amount, 10),
+		"outputMint":           q.Get("outputMint"),
+		"outAmount":            strconv.FormatUint(amount, 10),
+		"otherAmountThreshold": strconv.FormatUint(threshold, 10),
+		"swapMode":             swapMode,
+		"slippageBps":          slippageBps,
+		"platformFee":          nil,
+		"priceImpactPct":       "0",
+		"routePlan": []map[string]interface{}{
+			{
+				"swapInfo": map[string]interface{}{
+					"ammKey":     "FakeAmm111111111111111111111111111111111111",
+					"label":      "Fake",
+					"inputMint":  q.Get("inputMint"),
+					"outputMint": q.Get("outputMint"),
+					"inAmount":   strconv.FormatUint(amount, 10),
+					"outAmount":  strconv.FormatUint(amount, 10),
+					"feeAmount":  "0",
+					"feeMint":    q.Get("inputMint"),
+				},
+				"percent": 100,
+			},
+		},
+		"contextSlot": 1,
+		"timeTaken":   0.001,
+	}
+	if computedAutoSlippage != 0 {
+		quote["computedAutoSlippage"] = computedAutoSlippage
+	}
+
+	return quote
+}
+
+// defaultSwap builds a swap response for the request body, reporting dynamic slippage if it was requested.
+func defaultSwap(body []byte) map[string]interface{} {
+	swap := map[string]interface{}{
+		"swapTransaction":           DefaultSwapTransaction,
+		"lastValidBlockHeight":      DefaultLastValidBlockHeight,
+		"prioritizationFeeLamports": 0,
+		"computeUnitLimit":          DefaultComputeUnitLimit,
+		"prioritizationType": map[string]interface{}{
+			"computeBudget": map[string]interface{}{"microLamports": 0, "estimatedMicroLamports": 0},
+		},
+	}
+
+	var params v6.SwapParams
+	if err := json.Unmarshal(body, &params); err == nil && params.DynamicSlippage != nil {
+		swap["dynamicSlippageReport"] = map[string]interface{}{
+			"slippageBps":                  params.DynamicSlippage.MaxBps,
+			"otherAmount":                  nil,
+			"simulatedIncurredSlippageBps": nil,
+			"amplificationRatio":           nil,
+			"categoryName":                 "",
+			"heuristicMaxSlippageBps":      params.DynamicSlippage.MaxBps,
+		}
+	}
+
+	return swap
+}
+
+func defaultSwapInstructions() map[string]interface{} {
+	const programID = "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"
+
+	instruction := map[string]interface{}{
+		"programId": programID,
+		"accounts":  []interface{}{},
+		"data":      "",
+	}
+
+	return map[string]interface{}{
+		"tokenLedgerInstruction":      nil,
+		"computeBudgetInstructions":   []interface{}{},
+		"setupInstructions":           []interface{}{},
+		"swapInstruction":             instruction,
+		"cleanupInstruction":          instruction,
+		"otherInstructions":           []interface{}{},
+		"addressLookupTableAddresses": []string{},
+		"prioritizationFeeLamports":   0,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", v6.ContentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFault(w http.ResponseWriter, f *Fault) {
+	for k, v := range f.Header {
+		w.Header()[k] = v
+	}
+
+	status := f.StatusCode
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	if f.Body != "" {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(f.Body))
+		return
+	}
+
+	msg := f.Message
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	payload := map[string]string{"error": msg}
+	if f.ErrorCode != "" {
+		payload["errorCode"] = f.ErrorCode
+	}
+	writeJSON(w, status, payload)
+}
+
+// sleep waits for d or until ctx is done, reporting whether the full duration elapsed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,129 @@
+package jupitertest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	wSolMint      = solana.WrappedSOLMint
+	usdcMint      = solana.USDCMint
+	userPublicKey = solana.MustPublicKeyFromBase58("8HwPMNxtFDrvxXn1fJsAYB258TnA6Ydr1DWCtVYgRW4W")
+)
+
+func TestServer(t *testing.T) {
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+	quoteParams := v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000, SlippageBps: 100}
+
+	t.Run("default responses", func(t *testing.T) {
+		defer srv.Reset()
+
+		quote, err := c.Quote(quoteParams)
+		require.NoError(t, err)
+		assert.Equal(t, wSolMint, quote.InputMint)
+		assert.Equal(t, usdcMint, quote.OutputMint)
+		assert.EqualValues(t, 100000, quote.InAmount)
+		assert.EqualValues(t, 99000, quote.OtherAmountThreshold)
+		assert.Equal(t, 100, quote.SlippageBps)
+		require.Len(t, quote.RoutePlan, 1)
+
+		tx, err := c.Swap(v6.SwapParams{QuoteResponse: quote, UserPublicKey: wSolMint})
+		require.NoError(t, err)
+		assert.Equal(t, jupitertest.DefaultSwapTransaction, tx)
+
+		instructions, err := c.SwapInstructions(v6.SwapParams{QuoteResponse: quote, UserPublicKey: wSolMint})
+		require.NoError(t, err)
+		assert.NotEmpty(t, instructions.SwapInstruction.ProgramId)
+
+		assert.Len(t, srv.Requests(""), 3)
+		assert.Len(t, srv.Requests(jupitertest.EndpointQuote), 1)
+	})
+
+	t.Run("scripted responses", func(t *testing.T) {
+		defer srv.Reset()
+
+		srv.SetQuoteResponse(&v6.QuoteResponse{InputMint: wSolMint, OutAmount: 42})
+		srv.SetSwapResponse(&v6.SwapResponse{SwapTransaction: "scripted"})
+
+		quote, err := c.Quote(quoteParams)
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, quote.OutAmount)
+
+		tx, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		assert.Equal(t, "scripted", tx)
+	})
+
+	t.Run("custom handler", func(t *testing.T) {
+		defer srv.Reset()
+
+		srv.Handle(jupitertest.EndpointSwap, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"swapTransaction":"handled"}`))
+		})
+
+		tx, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		assert.Equal(t, "handled", tx)
+	})
+
+	t.Run("fault injection", func(t *testing.T) {
+		defer srv.Reset()
+
+		srv.InjectFault(jupitertest.EndpointQuote, jupitertest.Fault{
+			StatusCode: http.StatusBadRequest,
+			ErrorCode:  v6.ErrorCodeCouldNotFindAnyRoute,
+			Message:    "Could not find any route",
+		})
+		srv.InjectFault(jupitertest.EndpointQuote, jupitertest.Fault{StatusCode: http.StatusTooManyRequests})
+
+		_, err := c.Quote(quoteParams)
+		require.ErrorIs(t, err, v6.ErrNoRoute)
+
+		_, err = c.Quote(quoteParams)
+		require.ErrorIs(t, err, v6.ErrRateLimited)
+
+		_, err = c.Quote(quoteParams)
+		require.NoError(t, err)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		defer srv.Reset()
+
+		srv.SetLatency(jupitertest.EndpointSwap, 200*time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := c.SwapContext(ctx, v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("request assertions", func(t *testing.T) {
+		defer srv.Reset()
+
+		c := srv.Client(v6.WithAPIKey("key"))
+		_, err := c.Quote(quoteParams)
+		require.NoError(t, err)
+
+		req, ok := srv.LastRequest(jupitertest.EndpointQuote)
+		require.True(t, ok)
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "100", req.Query.Get("slippageBps"))
+		assert.Equal(t, "key", req.Header.Get(v6.HeaderAPIKey))
+
+		_, ok = srv.LastRequest(jupitertest.EndpointSwap)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,39 @@
+package v6
+
+import (
+	"context"
+	"net/http"
+)
+
+type (
+	// Call describes a single Jupiter API call passing through the middleware chain.
+	Call struct {
+		Operation Operation   // the operation being performed
+		Params    interface{} // QuoteParams for OperationQuote, SwapParams for OperationSwap and OperationSwapInstructions
+		Header    http.Header // extra headers to send with the request, e.g. set by a signing middleware. Sensitive ones are redacted from errors.
+	}
+
+	// Handler performs a call and returns its decoded response:
+	// *QuoteResponse for OperationQuote, *SwapResponse for OperationSwap
+	// and *SwapInstructionsResp for OperationSwapInstructions.
+	Handler func(ctx context.Context, call *Call) (interface{}, error)
+
+	// Middleware wraps a Handler to add behavior such as logging, metrics, tracing or fault injection.
+	// It may inspect or replace the call parameters, which are validated after the whole chain ran, mutate the call headers,
+	// short-circuit the call, or inspect the decoded response returned by next.
+	Middleware func(next Handler) Handler
+)
+
+// invoke runs the call through the client's middleware chain, ending with the given handler.
+func (c *Client) invoke(ctx context.Context, op Operation, params interface{}, final Handler) (interface{}, error) {
+	h := final
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+
+	return h(ctx, &Call{
+		Operation: op,
+		Params:    params,
+		Header:    make(http.Header),
+	})
+}
@@ -0,0 +1,129 @@
+package v6_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		switch r.URL.Path {
+		case "/quote":
+			_, _ = w.Write([]byte(`{"inputMint":"` + r.URL.Query().Get("inputMint") + `","inAmount":"` + r.URL.Query().Get("amount") + `"}`))
+		case "/swap":
+			_, _ = w.Write([]byte(`{"swapTransaction":"tx","lastValidBlockHeight":42}`))
+		case "/swap-instructions":
+			_, _ = w.Write([]byte(`{"prioritizationFeeLamports":7}`))
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("runs in order and sees typed calls", func(t *testing.T) {
+		var trace []string
+		record := func(name string) v6.Middleware {
+			return func(next v6.Handler) v6.Handler {
+				return func(ctx context.Context, call *v6.Call) (interface{}, error) {
+					trace = append(trace, name+" before "+string(call.Operation))
+					res, err := next(ctx, call)
+					trace = append(trace, name+" after "+string(call.Operation))
+					return res, err
+				}
+			}
+		}
+
+		var responses []interface{}
+		inspect := func(next v6.Handler) v6.Handler {
+			return func(ctx context.Context, call *v6.Call) (interface{}, error) {
+				res, err := next(ctx, call)
+				responses = append(responses, res)
+				return res, err
+			}
+		}
+
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithMiddleware(record("outer"), record("inner")), v6.WithMiddleware(inspect))
+
+		_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		require.NoError(t, err)
+		_, err = c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		_, err = c.SwapInstructions(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"outer before Quote", "inner before Quote", "inner after Quote", "outer after Quote",
+			"outer before Swap", "inner before Swap", "inner after Swap", "outer after Swap",
+			"outer before SwapInstructions", "inner before SwapInstructions", "inner after SwapInstructions", "outer after SwapInstructions",
+		}, trace)
+
+		require.Len(t, responses, 3)
+		assert.IsType(t, &v6.QuoteResponse{}, responses[0])
+		require.IsType(t, &v6.SwapResponse{}, responses[1])
+		assert.EqualValues(t, 42, responses[1].(*v6.SwapResponse).LastValidBlockHeight)
+		require.IsType(t, &v6.SwapInstructionsResp{}, responses[2])
+		assert.EqualValues(t, 7, responses[2].(*v6.SwapInstructionsResp).PrioritizationFeeLamports)
+	})
+
+	t.Run("can rewrite params and headers", func(t *testing.T) {
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithMiddleware(func(next v6.Handler) v6.Handler {
+			return func(ctx context.Context, call *v6.Call) (interface{}, error) {
+				params := call.Params.(v6.QuoteParams)
+				params.Amount = 42
+				call.Params = params
+				call.Header.Set("X-Signature", "signed")
+				return next(ctx, call)
+			}
+		}))
+
+		quote, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, quote.InAmount)
+		assert.Equal(t, "signed", gotHeader.Get("X-Signature"))
+	})
+
+	t.Run("rewritten params are validated", func(t *testing.T) {
+		var calls int
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithMiddleware(func(next v6.Handler) v6.Handler {
+			return func(ctx context.Context, call *v6.Call) (interface{}, error) {
+				calls++
+				params := call.Params.(v6.QuoteParams)
+				params.Amount = 0
+				call.Params = params
+				return next(ctx, call)
+			}
+		}))
+
+		gotHeader = nil
+		_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		assert.ErrorIs(t, err, v6.ErrInvalidParams)
+		assert.EqualError(t, err, "invalid quote params: amount: must be greater than 0")
+		assert.Equal(t, 1, calls)
+		assert.Nil(t, gotHeader, "invalid params must not be sent")
+	})
+
+	t.Run("can inject faults", func(t *testing.T) {
+		fault := errors.New("injected")
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithMiddleware(func(next v6.Handler) v6.Handler {
+			return func(ctx context.Context, call *v6.Call) (interface{}, error) {
+				if call.Operation == v6.OperationSwap {
+					return nil, fault
+				}
+				return next(ctx, call)
+			}
+		}))
+
+		_, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.ErrorIs(t, err, fault)
+
+		_, err = c.SwapInstructions(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,152 @@
+package v6
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned when a request is rejected by the client-side rate limiter,
+// either because fail fast is enabled or because the wait would exceed the context deadline.
+var ErrRateLimitExceeded = errors.New("client rate limit exceeded")
+
+// RateLimiter is a token bucket rate limiter safe for concurrent use.
+// It can be shared by several clients and goroutines.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a rate limiter allowing rate requests per second with bursts of up to burst requests.
+// The bucket starts full.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Rate returns the number of requests per second the limiter allows.
+func (l *RateLimiter) Rate() float64 {
+	return l.rate
+}
+
+// Burst returns the maximum number of requests the limiter allows at once.
+func (l *RateLimiter) Burst() int {
+	return int(l.burst)
+}
+
+// Tokens returns the current budget, that is the number of requests that can be made right now without waiting.
+// It is negative when callers are waiting for the budget to be replenished.
+func (l *RateLimiter) Tokens() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(time.Now())
+
+	return l.tokens
+}
+
+// Allow reports whether a request may be made now and consumes a token if so.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
+
+// Wait blocks until a request may be made or the context is done.
+// It fails immediately with ErrRateLimitExceeded if the wait would exceed the context deadline.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.advance(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return ErrRateLimitExceeded
+	}
+
+	wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(wait)) {
+		l.mu.Unlock()
+		return ErrRateLimitExceeded
+	}
+	l.tokens--
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back, so other callers do not wait for it.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// advance refills the bucket for the time elapsed since the last call.
+// The caller must hold the lock.
+func (l *RateLimiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last)
+	if elapsed <= 0 {
+		return
+	}
+	l.last = now
+
+	l.tokens += elapsed.Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// RateLimiter returns the rate limiter used for the given operation or nil if there is none.
+func (c *Client) RateLimiter(op Operation) *RateLimiter {
+	return c.rateLimiters[op]
+}
+
+// waitRateLimit waits for the rate limiter of the given operation, if any.
+func (c *Client) waitRateLimit(ctx context.Context, op Operation) error {
+	l := c.rateLimiters[op]
+	if l == nil {
+		return nil
+	}
+
+	if c.rateLimitFailFast {
+		if !l.Allow() {
+			return ErrRateLimitExceeded
+		}
+		return nil
+	}
+
+	return l.Wait(ctx)
+}
@@ -0,0 +1,102 @@
+package v6_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("allow consumes burst", func(t *testing.T) {
+		l := v6.NewRateLimiter(1, 3)
+		assert.InDelta(t, 3, l.Tokens(), 0.01)
+
+		for i := 0; i < 3; i++ {
+			assert.True(t, l.Allow())
+		}
+		assert.False(t, l.Allow())
+		assert.InDelta(t, 0, l.Tokens(), 0.01)
+	})
+
+	t.Run("wait blocks until refilled", func(t *testing.T) {
+		l := v6.NewRateLimiter(50, 1)
+		require.NoError(t, l.Wait(context.Background()))
+
+		start := time.Now()
+		require.NoError(t, l.Wait(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	})
+
+	t.Run("wait fails fast when deadline is too close", func(t *testing.T) {
+		l := v6.NewRateLimiter(0.1, 1)
+		require.NoError(t, l.Wait(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		require.ErrorIs(t, l.Wait(ctx), v6.ErrRateLimitExceeded)
+		assert.Less(t, time.Since(start), 10*time.Millisecond)
+	})
+
+	t.Run("shared across goroutines", func(t *testing.T) {
+		l := v6.NewRateLimiter(0.1, 5)
+
+		var allowed int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if l.Allow() {
+					atomic.AddInt32(&allowed, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.EqualValues(t, 5, atomic.LoadInt32(&allowed))
+	})
+}
+
+func TestClientRateLimit(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"swapTransaction":"tx"}`))
+	}))
+	defer srv.Close()
+
+	quoteLimiter := v6.NewRateLimiter(0.1, 1)
+	c := v6.NewClient(
+		v6.WithAPIURL(srv.URL),
+		v6.WithRateLimiter(quoteLimiter, v6.OperationQuote),
+		v6.WithRateLimitFailFast(true),
+	)
+
+	assert.Same(t, quoteLimiter, c.RateLimiter(v6.OperationQuote))
+	assert.Nil(t, c.RateLimiter(v6.OperationSwap))
+
+	params := v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000}
+	_, err := c.Quote(params)
+	require.NoError(t, err)
+
+	_, err = c.Quote(params)
+	require.ErrorIs(t, err, v6.ErrRateLimitExceeded)
+
+	// Swap has no limiter configured.
+	for i := 0; i < 3; i++ {
+		_, err = c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+	}
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,79 @@
+package v6
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// defaultMaxResponseBodySize is the default limit of a response body read by the client.
+	defaultMaxResponseBodySize = 10 << 20
+
+	// maxDrainSize limits how much of an unread response body is discarded to reuse the connection.
+	// Larger leftovers are cheaper to handle by closing the connection.
+	maxDrainSize = 256 << 10
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the configured limit.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// decodeResponse decodes the JSON body of a 200 response into v, or returns an *APIError for any other status code.
+// The response body is always drained and closed.
+func (c *Client) decodeResponse(method, endpoint string, resp *http.Response, v interface{}) error {
+	defer drainAndClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return c.newAPIError(method, endpoint, resp)
+	}
+
+	body := &limitedReader{r: resp.Body, n: c.maxResponseBodySize}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return fmt.Errorf("failed to read response: %w: limit is %d bytes", ErrResponseTooLarge, c.maxResponseBodySize)
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// drainAndClose discards what is left of the response body and closes it,
+// so the underlying connection can be reused.
+func drainAndClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	_ = resp.Body.Close()
+}
+
+// limitedReader reads from r until n bytes are read, then fails with ErrResponseTooLarge.
+// Unlike io.LimitReader it reports the overflow rather than a silent EOF.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Probe for more data to tell a body of exactly the limit from an oversized one.
+		var probe [1]byte
+		for {
+			n, err := l.r.Read(probe[:])
+			if n > 0 {
+				return 0, ErrResponseTooLarge
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
@@ -0,0 +1,82 @@
+package v6_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseBodiesAreReleased(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/quote":
+			if r.URL.Query().Get("amount") == "0" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"amount is zero","errorCode":"INVALID_AMOUNT"}`))
+				return
+			}
+			// Trailing data after the JSON value must be drained as well.
+			_, _ = w.Write([]byte(`{"inAmount":"100000"}` + strings.Repeat(" ", 4096)))
+		case "/swap":
+			_, _ = w.Write([]byte(`{"swapTransaction":"tx"}`))
+		case "/swap-instructions":
+			_, _ = w.Write([]byte(`{"prioritizationFeeLamports":1}`))
+		}
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	// Validation is disabled so that the zero amount reaches the server.
+	c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithHTTPClient(srv.Client()), v6.WithValidation(false))
+
+	for i := 0; i < 1000; i++ {
+		_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000})
+		require.NoError(t, err)
+
+		_, err = c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint})
+		require.ErrorIs(t, err, v6.ErrBadRequest)
+
+		_, err = c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+
+		_, err = c.SwapInstructions(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+	}
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&conns))
+}
+
+func TestMaxResponseBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"swapTransaction":"` + strings.Repeat("a", 1024) + `"}`))
+	}))
+	defer srv.Close()
+
+	t.Run("too large", func(t *testing.T) {
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithMaxResponseBodySize(512))
+
+		_, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.ErrorIs(t, err, v6.ErrResponseTooLarge)
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithMaxResponseBodySize(2048))
+
+		tx, err := c.Swap(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		assert.Len(t, tx, 1024)
+	})
+}
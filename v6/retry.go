@@ -0,0 +1,205 @@
+package v6
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryInitialBackoff = 200 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMultiplier     = 2
+)
+
+type (
+	// RetryPolicy configures how the client retries failed requests.
+	//
+	// Requests rejected before being processed (408, 429 and 503) are always retried.
+	// Transport errors and other 5xx responses are retried only for idempotent requests,
+	// unless RetryNonIdempotent is set. The Jupiter swap endpoints only build transactions
+	// and have no side effects, so enabling RetryNonIdempotent is safe for them.
+	RetryPolicy struct {
+		MaxAttempts        int                // Total number of attempts including the first one. Values below 2 disable retries.
+		InitialBackoff     time.Duration      // Delay before the first retry. Default is 200ms.
+		MaxBackoff         time.Duration      // Upper bound of the delay between attempts. Default is 5s.
+		Multiplier         float64            // Factor the delay grows by after each attempt. Default is 2.
+		Jitter             float64            // Fraction of the delay that is randomized, between 0 and 1. Default is 0.
+		MaxRetryAfter      time.Duration      // Upper bound of a honored Retry-After header. Default is no bound.
+		RetryNonIdempotent bool               // Retry POST requests on transport errors and 5xx responses as well.
+		OnAttempt          func(RetryAttempt) // Called after every attempt, whether it is going to be retried or not.
+	}
+
+	// RetryAttempt describes the outcome of a single request attempt.
+	RetryAttempt struct {
+		Method     string        // HTTP method of the request
+		Endpoint   string        // endpoint of the request, e.g. "/quote"
+		Attempt    int           // 1-based attempt number
+		StatusCode int           // HTTP status code, 0 if no response was received
+		Err        error         // transport error, nil if a response was received
+		Retry      bool          // whether another attempt will be made
+		Delay      time.Duration // delay before the next attempt, 0 if Retry is false
+	}
+)
+
+// DefaultRetryPolicy returns a RetryPolicy making up to 3 attempts with jittered exponential backoff.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		Jitter:         0.2,
+	}
+}
+
+// doWithRetry sends the request until it succeeds, fails permanently or the attempts are exhausted.
+// The last response is returned as is, so the caller can inspect a final non-200 status code.
+func (c *Client) doWithRetry(op Operation, endpoint string, req *http.Request) (*http.Response, error) {
+	p := c.retryPolicy
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		if err := c.waitRateLimit(ctx, op); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.client.Do(r)
+
+		retry := attempt < p.MaxAttempts && ctx.Err() == nil && p.retryable(req.Method, resp, err)
+		var delay time.Duration
+		if retry {
+			delay = p.backoff(attempt, resp)
+		}
+
+		if p.OnAttempt != nil {
+			a := RetryAttempt{
+				Method:   req.Method,
+				Endpoint: endpoint,
+				Attempt:  attempt,
+				Err:      c.redactError(err, r.Header),
+				Retry:    retry,
+				Delay:    delay,
+			}
+			if resp != nil {
+				a.StatusCode = resp.StatusCode
+			}
+			p.OnAttempt(a)
+		}
+
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			drainAndClose(resp)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether the outcome of an attempt is worth retrying.
+func (p *RetryPolicy) retryable(method string, resp *http.Response, err error) bool {
+	idempotent := method == http.MethodGet || method == http.MethodHead || p.RetryNonIdempotent
+
+	if err != nil {
+		return idempotent
+	}
+
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+
+	return false
+}
+
+// backoff returns the delay before the attempt following the given one.
+// A Retry-After header takes precedence over the computed delay if it is longer.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	initial, maxBackoff, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+	if multiplier < 1 {
+		multiplier = defaultRetryMultiplier
+	}
+
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if d > float64(maxBackoff) {
+		d = float64(maxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		d -= d * jitter * rand.Float64()
+	}
+	delay := time.Duration(d)
+
+	if resp != nil {
+		if ra, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxRetryAfter > 0 && ra > p.MaxRetryAfter {
+				ra = p.MaxRetryAfter
+			}
+			if ra > delay {
+				delay = ra
+			}
+		}
+	}
+
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
@@ -0,0 +1,139 @@
+package v6_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer fails the first n requests with the given status code and then responds with body.
+func flakyServer(t *testing.T, n int32, status int, header http.Header, body string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func TestRetryPolicy(t *testing.T) {
+	quoteParams := v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000}
+	swapParams := v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable, nil, `{"inAmount":"100000"}`)
+
+		var attempts []v6.RetryAttempt
+		policy := v6.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			OnAttempt:      func(a v6.RetryAttempt) { attempts = append(attempts, a) },
+		}
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithRetryPolicy(policy))
+
+		quote, err := c.Quote(quoteParams)
+		require.NoError(t, err)
+		assert.EqualValues(t, 100000, quote.InAmount)
+		assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+
+		require.Len(t, attempts, 3)
+		for i, a := range attempts[:2] {
+			assert.Equal(t, i+1, a.Attempt)
+			assert.Equal(t, http.StatusServiceUnavailable, a.StatusCode)
+			assert.Equal(t, "/quote", a.Endpoint)
+			assert.True(t, a.Retry)
+		}
+		assert.Equal(t, http.StatusOK, attempts[2].StatusCode)
+		assert.False(t, attempts[2].Retry)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		srv, calls := flakyServer(t, 10, http.StatusTooManyRequests, nil, "")
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithRetryPolicy(v6.RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		_, err := c.Quote(quoteParams)
+		require.ErrorIs(t, err, v6.ErrRateLimited)
+		assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	})
+
+	t.Run("honors retry-after", func(t *testing.T) {
+		srv, _ := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"120"}}, `{}`)
+
+		var delay time.Duration
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithRetryPolicy(v6.RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			MaxRetryAfter:  20 * time.Millisecond,
+			OnAttempt: func(a v6.RetryAttempt) {
+				if a.Retry {
+					delay = a.Delay
+				}
+			},
+		}))
+
+		_, err := c.Quote(quoteParams)
+		require.NoError(t, err)
+		assert.Equal(t, 20*time.Millisecond, delay)
+	})
+
+	t.Run("does not retry non-idempotent requests by default", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, http.StatusInternalServerError, nil, `{"swapTransaction":"tx"}`)
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithRetryPolicy(v6.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		}))
+
+		_, err := c.Swap(swapParams)
+		require.ErrorIs(t, err, v6.ErrServerError)
+		assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+	})
+
+	t.Run("retries non-idempotent requests when enabled", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, http.StatusInternalServerError, nil, `{"swapTransaction":"tx"}`)
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithRetryPolicy(v6.RetryPolicy{
+			MaxAttempts:        3,
+			InitialBackoff:     time.Millisecond,
+			RetryNonIdempotent: true,
+		}))
+
+		tx, err := c.Swap(swapParams)
+		require.NoError(t, err)
+		assert.Equal(t, "tx", tx)
+		assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	})
+
+	t.Run("stops on context cancellation", func(t *testing.T) {
+		srv, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil, "")
+		c := v6.NewClient(v6.WithAPIURL(srv.URL), v6.WithRetryPolicy(v6.RetryPolicy{
+			MaxAttempts:    10,
+			InitialBackoff: time.Second,
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.QuoteContext(ctx, quoteParams)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+	})
+}
@@ -0,0 +1,28 @@
+package v6
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qiruos/jupiter/solana"
+)
+
+// SignSwapTransaction signs the base64 encoded transaction returned by Swap with the signer of SwapParams.UserPublicKey.
+// It returns the signed transaction in wire format, ready to be sent to a Solana RPC node.
+func SignSwapTransaction(ctx context.Context, swapTransaction string, signer solana.Signer) ([]byte, error) {
+	tx, err := solana.TransactionFromBase64(swapTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode swap transaction: %w", err)
+	}
+
+	if err := tx.Sign(ctx, signer); err != nil {
+		return nil, fmt.Errorf("failed to sign swap transaction: %w", err)
+	}
+
+	return tx.MarshalBinary()
+}
+
+// Sign is like SignSwapTransaction for the transaction of the response.
+func (r *SwapResponse) Sign(ctx context.Context, signer solana.Signer) ([]byte, error) {
+	return SignSwapTransaction(ctx, r.SwapTransaction, signer)
+}
@@ -0,0 +1,64 @@
+package v6_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unsignedSwapTransaction returns a base64 encoded transaction paid by user, like the ones returned by /swap.
+func unsignedSwapTransaction(t *testing.T, user solana.PublicKey) string {
+	t.Helper()
+
+	tx, err := solana.TransactionFromBase64(jupitertest.DefaultSwapTransaction)
+	require.NoError(t, err)
+	tx.Message.AccountKeys[0] = user
+
+	s, err := tx.Base64()
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestSignSwapTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := solana.NewKeypair()
+	require.NoError(t, err)
+
+	t.Run("signs for the user", func(t *testing.T) {
+		srv := jupitertest.NewServer()
+		defer srv.Close()
+		srv.SetSwapResponse(&v6.SwapResponse{SwapTransaction: unsignedSwapTransaction(t, user.PublicKey()), LastValidBlockHeight: 1000})
+
+		c := srv.Client()
+		swap, err := c.SwapWithDetails(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: user.PublicKey()})
+		require.NoError(t, err)
+
+		signed, err := swap.Sign(ctx, user)
+		require.NoError(t, err)
+
+		tx, err := solana.TransactionFromBytes(signed)
+		require.NoError(t, err)
+		require.NoError(t, tx.Verify())
+
+		unsigned, err := swap.Transaction()
+		require.NoError(t, err)
+		assert.Equal(t, unsigned.Message, tx.Message)
+	})
+
+	t.Run("rejects other signers", func(t *testing.T) {
+		_, err := v6.SignSwapTransaction(ctx, jupitertest.DefaultSwapTransaction, user)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects invalid transactions", func(t *testing.T) {
+		_, err := v6.SignSwapTransaction(ctx, "tx", user)
+		assert.Error(t, err)
+	})
+}
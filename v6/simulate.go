@@ -0,0 +1,269 @@
+package v6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/qiruos/jupiter/utils"
+)
+
+type (
+	// SimulateOptions configures Simulate.
+	SimulateOptions struct {
+		Commitment         rpc.Commitment    // commitment of the balances and of the bank the swap is simulated against. Default is the node default.
+		InputTokenAccount  *solana.PublicKey // token account the input is spent from. Default is the associated token account of the user.
+		OutputTokenAccount *solana.PublicKey // token account receiving the output, to be set along SwapParams.DestinationTokenAccount. Default is the associated token account of the user.
+	}
+
+	// BalanceChange is the balance of a mint held by the user before and after a simulated swap.
+	BalanceChange struct {
+		Mint     solana.PublicKey
+		Accounts []solana.PublicKey // token account holding the balance, followed by the user for SOL
+		Pre      uint64
+		Post     uint64 // equal to Pre if the simulation failed
+	}
+
+	// SimulationReport is the expected outcome of a swap transaction, simulated before it is signed.
+	// The amounts are only simulated if Err is nil.
+	SimulationReport struct {
+		User   solana.PublicKey // fee payer of the transaction, whose balances are reported
+		Input  BalanceChange
+		Output BalanceChange
+
+		InputSpent     int64 // decrease of the input balance, bounded to int64
+		OutputReceived int64 // increase of the output balance, bounded to int64
+
+		SwapMode             string
+		QuotedInAmount       uint64 // QuoteResponse.InAmount
+		QuotedOutAmount      uint64 // QuoteResponse.OutAmount
+		OtherAmountThreshold uint64 // QuoteResponse.OtherAmountThreshold, the minimum output of ExactIn swaps or the maximum input of ExactOut swaps
+		SlippageBps          int64  // how much worse the simulated swap is than the quote: lower output for ExactIn swaps, higher input for ExactOut swaps. Negative if it is better.
+		WithinThreshold      bool   // whether the simulated amounts respect OtherAmountThreshold
+
+		UnitsConsumed uint64                // compute units consumed by the transaction
+		Logs          []string              // program logs
+		Slot          uint64                // slot at which the transaction was simulated
+		Err           *rpc.TransactionError // simulation error, nil if the swap succeeded
+		JupiterError  *solana.JupiterError  // Jupiter program error the simulation failed with, decoded from the logs or Err
+	}
+)
+
+// Simulate simulates the transaction of the swap response before it is signed, and reports the balance
+// changes of the user along with the compute units consumed and the program logs. The simulated amounts
+// are compared with the quote the swap was built from.
+//
+// The balances before the swap are fetched separately from the simulation, so they may be from an earlier
+// slot. SOL balances are the lamports of the user and its wrapped SOL account, so they include the
+// transaction fee if the node charges it in simulations.
+//
+// A failed simulation is reported in SimulationReport.Err: only RPC failures are returned as errors.
+func Simulate(ctx context.Context, client *rpc.Client, quote *QuoteResponse, swap *SwapResponse, opts *SimulateOptions) (*SimulationReport, error) {
+	if opts == nil {
+		opts = &SimulateOptions{}
+	}
+
+	tx, err := swap.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.Message.AccountKeys) == 0 {
+		return nil, fmt.Errorf("swap transaction has no fee payer")
+	}
+
+	report := &SimulationReport{
+		User:                 tx.Message.AccountKeys[0],
+		SwapMode:             quote.SwapMode,
+		QuotedInAmount:       quote.InAmount,
+		QuotedOutAmount:      quote.OutAmount,
+		OtherAmountThreshold: quote.OtherAmountThreshold,
+	}
+
+	if report.Input, err = preBalance(ctx, client, report.User, quote.InputMint, opts.InputTokenAccount, opts.Commitment); err != nil {
+		return nil, fmt.Errorf("failed to fetch input balance: %w", err)
+	}
+	if report.Output, err = preBalance(ctx, client, report.User, quote.OutputMint, opts.OutputTokenAccount, opts.Commitment); err != nil {
+		return nil, fmt.Errorf("failed to fetch output balance: %w", err)
+	}
+
+	accounts := append(append([]solana.PublicKey(nil), report.Input.Accounts...), report.Output.Accounts...)
+	result, err := client.SimulateTransaction(ctx, tx, &rpc.SimulateTransactionOptions{
+		ReplaceRecentBlockhash: true,
+		Commitment:             opts.Commitment,
+		Accounts:               accounts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate swap transaction: %w", err)
+	}
+	report.UnitsConsumed, report.Logs, report.Slot, report.Err = result.UnitsConsumed, result.Logs, result.Slot, result.Err
+
+	report.Input.Post, report.Output.Post = report.Input.Pre, report.Output.Pre
+	if result.Err != nil {
+		// The logs tell Jupiter errors from errors of the programs it invokes, which fail its instruction too.
+		// Missing or truncated logs may not show the error, which is then decoded from the failing instruction.
+		jupErr, ok, innerFailed := jupiterErrorFromLogs(result.Logs)
+		if !ok && !innerFailed {
+			jupErr, ok = JupiterErrorOf(tx, result.Err)
+		}
+		if ok {
+			report.JupiterError = &jupErr
+		}
+		return report, nil
+	}
+
+	if len(result.Accounts) != len(accounts) {
+		return nil, fmt.Errorf("simulation returned %d accounts, want %d", len(result.Accounts), len(accounts))
+	}
+	n := len(report.Input.Accounts)
+	if report.Input.Post, err = balance(report.Input.Mint, accounts[:n], result.Accounts[:n]); err != nil {
+		return nil, fmt.Errorf("invalid simulated input balance: %w", err)
+	}
+	if report.Output.Post, err = balance(report.Output.Mint, accounts[n:], result.Accounts[n:]); err != nil {
+		return nil, fmt.Errorf("invalid simulated output balance: %w", err)
+	}
+
+	report.InputSpent = clampInt64(amountDiff(report.Input.Pre, report.Input.Post))
+	report.OutputReceived = clampInt64(amountDiff(report.Output.Post, report.Output.Pre))
+	report.SlippageBps, report.WithinThreshold = simulatedSlippage(report)
+
+	return report, nil
+}
+
+// simulatedSlippage compares the simulated amounts of the report with its quoted ones, see SimulationReport.SlippageBps.
+func simulatedSlippage(r *SimulationReport) (slippageBps int64, withinThreshold bool) {
+	spent, received := amountDiff(r.Input.Pre, r.Input.Post), amountDiff(r.Output.Post, r.Output.Pre)
+	threshold := utils.NewAmount(r.OtherAmountThreshold)
+
+	ref, slippage := utils.NewAmount(r.QuotedOutAmount), utils.NewAmount(r.QuotedOutAmount).Sub(received)
+	withinThreshold = received.Cmp(threshold) >= 0
+	if r.SwapMode == SwapModeExactOut {
+		ref, slippage = utils.NewAmount(r.QuotedInAmount), spent.Sub(utils.NewAmount(r.QuotedInAmount))
+		withinThreshold = spent.Cmp(threshold) <= 0
+	}
+	return bps(slippage, ref), withinThreshold
+}
+
+// amountDiff returns a - b, which may be negative.
+func amountDiff(a, b uint64) utils.Amount {
+	return utils.NewAmount(a).Sub(utils.NewAmount(b))
+}
+
+// preBalance returns the balance of the mint held by the user before the swap, in the given token account
+// or in its associated token account.
+func preBalance(ctx context.Context, client *rpc.Client, user, mint solana.PublicKey, tokenAccount *solana.PublicKey, commitment rpc.Commitment) (BalanceChange, error) {
+	b := BalanceChange{Mint: mint}
+
+	if tokenAccount != nil {
+		b.Accounts = append(b.Accounts, *tokenAccount)
+	} else {
+		program := solana.TokenProgramID
+		if mint != solana.WrappedSOLMint {
+			account, err := client.GetAccountInfo(ctx, mint, commitment)
+			if err != nil {
+				return b, fmt.Errorf("failed to fetch mint %s: %w", mint, err)
+			}
+			program = account.Owner
+		}
+		ata, err := solana.FindAssociatedTokenAddress(user, mint, program)
+		if err != nil {
+			return b, err
+		}
+		b.Accounts = append(b.Accounts, ata)
+	}
+	if mint == solana.WrappedSOLMint {
+		// Wrapped SOL accounts may be created and closed by the swap, moving lamports from and to the user.
+		b.Accounts = append(b.Accounts, user)
+	}
+
+	accounts := make([]*rpc.Account, len(b.Accounts))
+	for i, address := range b.Accounts {
+		account, err := client.GetAccountInfo(ctx, address, commitment)
+		if err != nil && !errors.Is(err, rpc.ErrAccountNotFound) {
+			return b, err
+		}
+		accounts[i] = account
+	}
+
+	var err error
+	b.Pre, err = balance(mint, b.Accounts, accounts)
+	return b, err
+}
+
+// balance returns the balance of the mint held in the accounts at the given addresses, nil if they do not exist.
+// SOL balances are counted in lamports, so both token accounts and wallets can hold them.
+func balance(mint solana.PublicKey, addresses []solana.PublicKey, accounts []*rpc.Account) (uint64, error) {
+	var total uint64
+	for i, account := range accounts {
+		switch {
+		case account == nil:
+		case mint == solana.WrappedSOLMint:
+			total += account.Lamports
+		default:
+			token, err := rpc.ParseTokenAccount(account.Data)
+			if err != nil {
+				return 0, fmt.Errorf("invalid token account %s: %w", addresses[i], err)
+			}
+			if token.Mint != mint {
+				return 0, fmt.Errorf("token account %s holds %s, not %s", addresses[i], token.Mint, mint)
+			}
+			total += token.Amount
+		}
+	}
+
+	return total, nil
+}
+
+var (
+	logInvokeRegexp      = regexp.MustCompile(`^Program (\w+) invoke \[\d+\]$`)
+	logReturnRegexp      = regexp.MustCompile(`^Program (\w+) (?:success$|(failed): )`)
+	logCustomErrorRegexp = regexp.MustCompile(`^Program (\w+) failed: custom program error: 0x([0-9a-fA-F]+)$`)
+	logAnchorErrorRegexp = regexp.MustCompile(`^Program log: AnchorError .*Error Number: (\d+)\.`)
+)
+
+// JupiterErrorFromLogs returns the Jupiter program error of a failed transaction from its program logs:
+// the Anchor error logged by a Jupiter aggregator v6 instruction, or the custom program error it failed with.
+// Errors of the programs invoked by Jupiter, which its instruction fails with too, are not Jupiter errors.
+func JupiterErrorFromLogs(logs []string) (solana.JupiterError, bool) {
+	jupErr, ok, _ := jupiterErrorFromLogs(logs)
+	return jupErr, ok
+}
+
+// jupiterErrorFromLogs is JupiterErrorFromLogs, also reporting whether the Jupiter instruction failed
+// with the error of a program it invoked.
+func jupiterErrorFromLogs(logs []string) (jupErr solana.JupiterError, ok, innerFailed bool) {
+	jupiter := solana.JupiterAggregatorV6ProgramID.String()
+
+	var invoked []string     // programs being executed, the innermost last
+	lastInnerFailed := false // whether the last program returning was invoked by another one and failed
+	for _, line := range logs {
+		if m := logInvokeRegexp.FindStringSubmatch(line); m != nil {
+			invoked = append(invoked, m[1])
+			continue
+		}
+		if m := logAnchorErrorRegexp.FindStringSubmatch(line); m != nil && len(invoked) > 0 && invoked[len(invoked)-1] == jupiter {
+			if code, err := strconv.ParseUint(m[1], 10, 32); err == nil {
+				return solana.JupiterError(code), true, false
+			}
+		}
+		if m := logCustomErrorRegexp.FindStringSubmatch(line); m != nil && m[1] == jupiter {
+			if lastInnerFailed {
+				return 0, false, true
+			}
+			if code, err := strconv.ParseUint(m[2], 16, 32); err == nil {
+				return solana.JupiterError(code), true, false
+			}
+		}
+		if m := logReturnRegexp.FindStringSubmatch(line); m != nil {
+			lastInnerFailed = m[2] != "" && len(invoked) > 1
+			if len(invoked) > 0 {
+				invoked = invoked[:len(invoked)-1]
+			}
+		}
+	}
+
+	return 0, false, false
+}
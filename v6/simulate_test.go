@@ -0,0 +1,310 @@
+package v6_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/qiruos/jupiter/solana/rpc/rpctest"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slippageLogs are the logs of a swap transaction failing with SlippageToleranceExceeded.
+var slippageLogs = []string{
+	"Program ComputeBudget111111111111111111111111111111 invoke [1]",
+	"Program ComputeBudget111111111111111111111111111111 success",
+	"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+	"Program log: Instruction: Route",
+	"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [2]",
+	"Program log: Instruction: Swap",
+	"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo consumed 40000 of 1360000 compute units",
+	"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo success",
+	"Program log: AnchorError occurred. Error Code: SlippageToleranceExceeded. Error Number: 6001. Error Message: Slippage tolerance exceeded.",
+	"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 consumed 60000 of 1400000 compute units",
+	"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 failed: custom program error: 0x1771",
+}
+
+func TestSimulate(t *testing.T) {
+	ctx := context.Background()
+
+	user, err := solana.NewKeypair()
+	require.NoError(t, err)
+	wSolATA, err := solana.FindAssociatedTokenAddress(user.PublicKey(), wSolMint, solana.TokenProgramID)
+	require.NoError(t, err)
+	usdcATA, err := solana.FindAssociatedTokenAddress(user.PublicKey(), usdcMint, solana.TokenProgramID)
+	require.NoError(t, err)
+
+	swap := &v6.SwapResponse{SwapTransaction: unsignedSwapTransaction(t, user.PublicKey())}
+	quote := &v6.QuoteResponse{
+		InputMint:            wSolMint,
+		InAmount:             100000,
+		OutputMint:           usdcMint,
+		OutAmount:            14951,
+		OtherAmountThreshold: 14877,
+		SwapMode:             v6.SwapModeExactIn,
+		SlippageBps:          50,
+	}
+
+	// newNode returns a fake RPC node where the user wraps SOL to receive the given amount of USDC.
+	newNode := func(t *testing.T, received uint64) *rpctest.Server {
+		node := rpctest.NewServer()
+		t.Cleanup(node.Close)
+
+		node.SetAccount(usdcMint, &rpc.Account{Lamports: 1, Owner: solana.TokenProgramID})
+		node.SetAccount(user.PublicKey(), &rpc.Account{Lamports: 1000000000, Owner: solana.SystemProgramID})
+		node.SetAccount(usdcATA, rpctest.TokenAccount(usdcMint, user.PublicKey(), 5000))
+
+		// The wrapped SOL account is created and closed by the swap, and the user pays a 5000 lamports fee.
+		node.SetSimulatedAccount(user.PublicKey(), &rpc.Account{Lamports: 1000000000 - 100000 - 5000, Owner: solana.SystemProgramID})
+		node.SetSimulatedAccount(wSolATA, nil)
+		node.SetSimulatedAccount(usdcATA, rpctest.TokenAccount(usdcMint, user.PublicKey(), 5000+received))
+		return node
+	}
+
+	t.Run("success", func(t *testing.T) {
+		node := newNode(t, 14900)
+		node.SetSimulationResult(&rpc.SimulationResult{UnitsConsumed: 98765, Logs: []string{"Program log: Instruction: Route"}})
+
+		report, err := v6.Simulate(ctx, node.Client(), quote, swap, nil)
+		require.NoError(t, err)
+		assert.Equal(t, user.PublicKey(), report.User)
+		assert.Equal(t, v6.BalanceChange{Mint: wSolMint, Accounts: []solana.PublicKey{wSolATA, user.PublicKey()}, Pre: 1000000000, Post: 999895000}, report.Input)
+		assert.Equal(t, v6.BalanceChange{Mint: usdcMint, Accounts: []solana.PublicKey{usdcATA}, Pre: 5000, Post: 19900}, report.Output)
+		assert.EqualValues(t, 105000, report.InputSpent)
+		assert.EqualValues(t, 14900, report.OutputReceived)
+		assert.EqualValues(t, 34, report.SlippageBps)
+		assert.True(t, report.WithinThreshold)
+		assert.EqualValues(t, 98765, report.UnitsConsumed)
+		assert.Equal(t, []string{"Program log: Instruction: Route"}, report.Logs)
+		assert.EqualValues(t, rpctest.DefaultSlot, report.Slot)
+		assert.Nil(t, report.Err)
+		assert.Nil(t, report.JupiterError)
+
+		var params []json.RawMessage
+		require.NoError(t, node.Requests(rpctest.MethodSimulateTransaction)[0].DecodeParams(&params))
+		var config struct {
+			SigVerify              bool `json:"sigVerify"`
+			ReplaceRecentBlockhash bool `json:"replaceRecentBlockhash"`
+			Accounts               struct {
+				Addresses []solana.PublicKey `json:"addresses"`
+			} `json:"accounts"`
+		}
+		require.NoError(t, json.Unmarshal(params[1], &config))
+		assert.False(t, config.SigVerify)
+		assert.True(t, config.ReplaceRecentBlockhash)
+		assert.Equal(t, []solana.PublicKey{wSolATA, user.PublicKey(), usdcATA}, config.Accounts.Addresses)
+	})
+
+	t.Run("below threshold", func(t *testing.T) {
+		report, err := v6.Simulate(ctx, newNode(t, 14000).Client(), quote, swap, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 14000, report.OutputReceived)
+		assert.EqualValues(t, 636, report.SlippageBps)
+		assert.False(t, report.WithinThreshold)
+	})
+
+	t.Run("exact out", func(t *testing.T) {
+		quote := &v6.QuoteResponse{
+			InputMint:            wSolMint,
+			InAmount:             100000,
+			OutputMint:           usdcMint,
+			OutAmount:            14900,
+			OtherAmountThreshold: 100500,
+			SwapMode:             v6.SwapModeExactOut,
+		}
+
+		report, err := v6.Simulate(ctx, newNode(t, 14900).Client(), quote, swap, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 105000, report.InputSpent)
+		assert.EqualValues(t, 500, report.SlippageBps)
+		assert.False(t, report.WithinThreshold)
+	})
+
+	t.Run("large amounts", func(t *testing.T) {
+		quote := *quote
+		quote.OutAmount, quote.OtherAmountThreshold = 9223372036854770807, 0
+
+		// Just over 1 bps less than quoted, which float64 math truncates to 0.
+		report, err := v6.Simulate(ctx, newNode(t, 9222449699651085329).Client(), &quote, swap, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, report.SlippageBps)
+	})
+
+	t.Run("balances above int64", func(t *testing.T) {
+		quote := *quote
+		quote.OutAmount, quote.OtherAmountThreshold = 9223372036854775818, 9223372036854775000
+
+		// The output balance grows by 2^63+10, past the int64 range.
+		report, err := v6.Simulate(ctx, newNode(t, 9223372036854775818).Client(), &quote, swap, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(9223372036854780818), report.Output.Post)
+		assert.EqualValues(t, math.MaxInt64, report.OutputReceived)
+		assert.EqualValues(t, 0, report.SlippageBps)
+		assert.True(t, report.WithinThreshold)
+	})
+
+	t.Run("slippage failure", func(t *testing.T) {
+		node := newNode(t, 0)
+		node.SetSimulationResult(&rpc.SimulationResult{
+			Err:           &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[2,{"Custom":6001}]}`)},
+			Logs:          slippageLogs,
+			UnitsConsumed: 60000,
+		})
+
+		report, err := v6.Simulate(ctx, node.Client(), quote, swap, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.Err)
+		require.NotNil(t, report.JupiterError)
+		assert.Equal(t, solana.JupiterErrorSlippageToleranceExceeded, *report.JupiterError)
+		assert.EqualValues(t, 60000, report.UnitsConsumed)
+		assert.Equal(t, report.Input.Pre, report.Input.Post)
+		assert.Zero(t, report.InputSpent)
+		assert.Zero(t, report.OutputReceived)
+		assert.False(t, report.WithinThreshold)
+	})
+
+	t.Run("jupiter error without logs", func(t *testing.T) {
+		node := newNode(t, 0)
+		node.SetSimulationResult(&rpc.SimulationResult{
+			Err: &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[2,{"Custom":6017}]}`)},
+		})
+
+		report, err := v6.Simulate(ctx, node.Client(), quote, swap, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.JupiterError)
+		assert.Equal(t, solana.JupiterErrorExactOutAmountNotMatched, *report.JupiterError)
+	})
+
+	t.Run("jupiter error with truncated logs", func(t *testing.T) {
+		node := newNode(t, 0)
+		node.SetSimulationResult(&rpc.SimulationResult{
+			Err:  &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[2,{"Custom":6001}]}`)},
+			Logs: append(append([]string(nil), slippageLogs[:6]...), "Log truncated"),
+		})
+
+		report, err := v6.Simulate(ctx, node.Client(), quote, swap, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.JupiterError)
+		assert.Equal(t, solana.JupiterErrorSlippageToleranceExceeded, *report.JupiterError)
+	})
+
+	t.Run("inner program error", func(t *testing.T) {
+		node := newNode(t, 0)
+		node.SetSimulationResult(&rpc.SimulationResult{
+			Err: &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[2,{"Custom":6001}]}`)},
+			Logs: []string{
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [2]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo failed: custom program error: 0x1771",
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 failed: custom program error: 0x1771",
+			},
+		})
+
+		report, err := v6.Simulate(ctx, node.Client(), quote, swap, nil)
+		require.NoError(t, err)
+		require.NotNil(t, report.Err)
+		assert.Nil(t, report.JupiterError)
+	})
+
+	t.Run("token accounts", func(t *testing.T) {
+		destination := solana.MustPublicKeyFromBase58("DAr8Qy1gj7YM5KMY5iDZtNVZ624mPg1hrCkAtm4VhcVv")
+		node := newNode(t, 0)
+		node.SetAccount(destination, rpctest.TokenAccount(usdcMint, destination, 7))
+		node.SetSimulatedAccount(destination, rpctest.TokenAccount(usdcMint, destination, 14907))
+
+		report, err := v6.Simulate(ctx, node.Client(), quote, swap, &v6.SimulateOptions{OutputTokenAccount: &destination})
+		require.NoError(t, err)
+		assert.Equal(t, []solana.PublicKey{destination}, report.Output.Accounts)
+		assert.EqualValues(t, 14900, report.OutputReceived)
+
+		_, err = v6.Simulate(ctx, node.Client(), quote, swap, &v6.SimulateOptions{OutputTokenAccount: &usdcATA, InputTokenAccount: &usdcATA})
+		require.NoError(t, err)
+
+		_, err = v6.Simulate(ctx, node.Client(), &v6.QuoteResponse{InputMint: usdcMint, OutputMint: wSolMint}, swap, &v6.SimulateOptions{InputTokenAccount: &wSolATA})
+		assert.NoError(t, err, "missing token accounts hold no balance")
+
+		node.SetAccount(wSolATA, rpctest.TokenAccount(wSolMint, user.PublicKey(), 0))
+		_, err = v6.Simulate(ctx, node.Client(), &v6.QuoteResponse{InputMint: usdcMint, OutputMint: wSolMint}, swap, &v6.SimulateOptions{InputTokenAccount: &wSolATA})
+		assert.ErrorContains(t, err, "holds So11111111111111111111111111111111111111112, not EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	})
+
+	t.Run("unknown mint", func(t *testing.T) {
+		node := newNode(t, 0)
+		node.SetAccount(usdcMint, nil)
+
+		_, err := v6.Simulate(ctx, node.Client(), quote, swap, nil)
+		assert.ErrorIs(t, err, rpc.ErrAccountNotFound)
+		assert.Empty(t, node.Requests(rpctest.MethodSimulateTransaction))
+	})
+}
+
+func TestJupiterErrorFromLogs(t *testing.T) {
+	tests := []struct {
+		name   string
+		logs   []string
+		want   solana.JupiterError
+		wantOK bool
+	}{
+		{name: "anchor error", logs: slippageLogs, want: solana.JupiterErrorSlippageToleranceExceeded, wantOK: true},
+		{
+			name: "custom program error",
+			logs: []string{
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 failed: custom program error: 0x1781",
+			},
+			want:   solana.JupiterErrorExactOutAmountNotMatched,
+			wantOK: true,
+		},
+		{
+			name: "inner program error",
+			logs: []string{
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [2]",
+				"Program log: AnchorError occurred. Error Code: ExceededAmountSlippageTolerance. Error Number: 6003. Error Message: Exceeded amount slippage tolerance.",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo failed: custom program error: 0x1773",
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 failed: custom program error: 0x1773",
+			},
+		},
+		{
+			name: "inner program success followed by a custom program error",
+			logs: []string{
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [2]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo success",
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 failed: custom program error: 0x1771",
+			},
+			want:   solana.JupiterErrorSlippageToleranceExceeded,
+			wantOK: true,
+		},
+		{
+			name: "inner program failure propagated by jupiter",
+			logs: []string{
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [2]",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo failed: custom program error: 0x1771",
+				"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 failed: custom program error: 0x1771",
+			},
+		},
+		{
+			name: "other program",
+			logs: []string{
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo invoke [1]",
+				"Program log: AnchorError occurred. Error Code: ExceededAmountSlippageTolerance. Error Number: 6003. Error Message: Exceeded amount slippage tolerance.",
+				"Program LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo failed: custom program error: 0x1773",
+			},
+		},
+		{name: "no logs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := v6.JupiterErrorFromLogs(tt.logs)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
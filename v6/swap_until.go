@@ -0,0 +1,213 @@
+package v6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/qiruos/jupiter/utils"
+)
+
+const (
+	defaultSwapUntilMaxAttempts     = 3
+	defaultSwapUntilSlippageStepBps = 50
+)
+
+// Errors returned by SwapUntil when the swap could not be confirmed.
+var (
+	ErrSwapFailed              = errors.New("swap failed")
+	ErrSwapAttemptsExhausted   = errors.New("swap attempts exhausted")
+	ErrSlippageBudgetExhausted = errors.New("slippage budget exhausted")
+	ErrPriceDriftExceeded      = errors.New("price drift exceeded")
+)
+
+type (
+	// SwapUntilOptions configures SwapUntil.
+	SwapUntilOptions struct {
+		Signer   solana.Signer // required. Signs the transactions for SwapParams.UserPublicKey
+		Executor *Executor     // required. Sends the transactions and waits for their outcome
+
+		MaxAttempts      int    // Total number of attempts including the first one. Default is 3.
+		SlippageStepBps  uint64 // Slippage added after a SlippageToleranceExceeded failure. Default is 50.
+		MaxSlippageBps   uint64 // Upper bound of the slippage. Default is the slippage of the first quote plus (MaxAttempts-1)*SlippageStepBps, one step per retry.
+		MaxPriceDriftBps uint64 // How much worse a new quote can be than the first one, see SwapAttempt.PriceDriftBps. Default is no bound.
+
+		OnAttempt func(SwapAttempt) // Called after every attempt.
+	}
+
+	// SwapAttempt describes a single attempt of SwapUntil.
+	SwapAttempt struct {
+		Attempt       int              // 1-based attempt number
+		Quote         *QuoteResponse   // quote of the attempt, nil if quoting failed
+		SlippageBps   uint64           // slippage of the quote
+		PriceDriftBps int64            // how much worse the quote is than the first one, negative if it is better
+		Signature     solana.Signature // signature of the transaction, zero if it was not sent
+		Result        *ExecutionResult // outcome of the transaction, nil if it was not sent
+		Err           error            // error ending the attempt, nil if the swap was confirmed
+	}
+
+	// SwapUntilReport reports the attempts made by SwapUntil.
+	SwapUntilReport struct {
+		Attempts []SwapAttempt
+	}
+)
+
+// Confirmed returns the confirmed attempt, or nil if the swap was not confirmed.
+func (r *SwapUntilReport) Confirmed() *SwapAttempt {
+	if n := len(r.Attempts); n > 0 && r.Attempts[n-1].Result != nil && r.Attempts[n-1].Result.Status == ExecutionConfirmed {
+		return &r.Attempts[n-1]
+	}
+	return nil
+}
+
+// SwapUntil quotes, swaps and executes until the swap is confirmed, requoting when the transaction
+// expires or fails with SlippageToleranceExceeded. A slippage failure raises the slippage by
+// SlippageStepBps up to MaxSlippageBps, disabling autoSlippage. New quotes worse than the first one
+// by more than MaxPriceDriftBps are not executed.
+//
+// The report lists every attempt, also when an error is returned. The error wraps ErrSwapFailed,
+// ErrSwapAttemptsExhausted, ErrSlippageBudgetExhausted or ErrPriceDriftExceeded if the budget did
+// not allow confirming the swap, or is the error of the failing API or RPC call.
+func (c *Client) SwapUntil(ctx context.Context, quoteParams QuoteParams, swapParams SwapParams, opts SwapUntilOptions) (*SwapUntilReport, error) {
+	if opts.Signer == nil || opts.Executor == nil {
+		return nil, fmt.Errorf("signer and executor are required")
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultSwapUntilMaxAttempts
+	}
+	if opts.SlippageStepBps == 0 {
+		opts.SlippageStepBps = defaultSwapUntilSlippageStepBps
+	}
+
+	report := &SwapUntilReport{}
+	record := func(a SwapAttempt) {
+		report.Attempts = append(report.Attempts, a)
+		if opts.OnAttempt != nil {
+			opts.OnAttempt(a)
+		}
+	}
+
+	var first *QuoteResponse
+	for attempt := 1; ; attempt++ {
+		a := SwapAttempt{Attempt: attempt}
+
+		quote, err := c.QuoteContext(ctx, quoteParams)
+		if err != nil {
+			a.Err = err
+			record(a)
+			return report, err
+		}
+		a.Quote, a.SlippageBps = quote, uint64(quote.SlippageBps)
+		if first == nil {
+			first = quote
+			if opts.MaxSlippageBps == 0 {
+				opts.MaxSlippageBps = a.SlippageBps + uint64(opts.MaxAttempts-1)*opts.SlippageStepBps
+			}
+		}
+
+		a.PriceDriftBps = priceDriftBps(first, quote)
+		if opts.MaxPriceDriftBps > 0 && a.PriceDriftBps > int64(opts.MaxPriceDriftBps) {
+			a.Err = fmt.Errorf("%w: quote is %d bps worse than the first one, limit is %d", ErrPriceDriftExceeded, a.PriceDriftBps, opts.MaxPriceDriftBps)
+			record(a)
+			return report, a.Err
+		}
+
+		swapParams.QuoteResponse = quote
+		swap, err := c.SwapWithDetailsContext(ctx, swapParams)
+		if err != nil {
+			a.Err = err
+			record(a)
+			return report, err
+		}
+
+		result, err := opts.Executor.ExecuteSwap(ctx, swap, opts.Signer)
+		if err != nil {
+			a.Err = err
+			record(a)
+			return report, err
+		}
+		a.Signature, a.Result = result.Signature, result
+
+		switch result.Status {
+		case ExecutionConfirmed:
+			record(a)
+			return report, nil
+		case ExecutionFailed:
+			tx, _ := swap.Transaction()
+			if jupErr, ok := JupiterErrorOf(tx, result.Err); !ok || jupErr != solana.JupiterErrorSlippageToleranceExceeded {
+				a.Err = fmt.Errorf("%w: %v", ErrSwapFailed, result.Err)
+				record(a)
+				return report, a.Err
+			}
+			if a.SlippageBps >= opts.MaxSlippageBps {
+				a.Err = fmt.Errorf("%w: slippage tolerance of %d bps exceeded, limit is %d", ErrSlippageBudgetExhausted, a.SlippageBps, opts.MaxSlippageBps)
+				record(a)
+				return report, a.Err
+			}
+			quoteParams.AutoSlippage, quoteParams.MaxAutoSlippageBps, quoteParams.AutoSlippageCollisionUsdValue = false, 0, 0
+			quoteParams.SlippageBps = a.SlippageBps + opts.SlippageStepBps
+			if quoteParams.SlippageBps > opts.MaxSlippageBps {
+				quoteParams.SlippageBps = opts.MaxSlippageBps
+			}
+			a.Err = solana.JupiterErrorSlippageToleranceExceeded
+		case ExecutionExpired:
+			a.Err = fmt.Errorf("transaction expired at block height %d", result.BlockHeight)
+		}
+
+		if attempt >= opts.MaxAttempts {
+			a.Err = fmt.Errorf("%w after %d attempts: %v", ErrSwapAttemptsExhausted, attempt, a.Err)
+			record(a)
+			return report, a.Err
+		}
+		record(a)
+	}
+}
+
+// priceDriftBps returns how much worse the quote is than the reference one, in bps of the reference:
+// a lower output amount for ExactIn swaps, a higher input amount for ExactOut swaps.
+func priceDriftBps(reference, quote *QuoteResponse) int64 {
+	ref, drift := utils.NewAmount(reference.OutAmount), utils.NewAmount(reference.OutAmount).Sub(utils.NewAmount(quote.OutAmount))
+	if quote.SwapMode == SwapModeExactOut {
+		ref, drift = utils.NewAmount(reference.InAmount), utils.NewAmount(quote.InAmount).Sub(utils.NewAmount(reference.InAmount))
+	}
+	return bps(drift, ref)
+}
+
+// bps returns amount in bps of ref, truncated towards zero and bounded to int64. It is 0 if ref is zero.
+func bps(amount, ref utils.Amount) int64 {
+	if ref.IsZero() {
+		return 0
+	}
+	return clampInt64(amount.Mul(utils.NewAmount(10000)).Quo(ref))
+}
+
+// clampInt64 returns the amount bounded to int64.
+func clampInt64(a utils.Amount) int64 {
+	i := a.Big()
+	switch {
+	case i.IsInt64():
+		return i.Int64()
+	case i.Sign() > 0:
+		return math.MaxInt64
+	default:
+		return math.MinInt64
+	}
+}
+
+// JupiterErrorOf returns the Jupiter program error of a failed transaction, if the failing instruction
+// is a Jupiter aggregator v6 instruction returning a custom program error.
+func JupiterErrorOf(tx *solana.Transaction, err *rpc.TransactionError) (solana.JupiterError, bool) {
+	if tx == nil || err == nil || err.Custom == nil || err.InstructionIndex < 0 || err.InstructionIndex >= len(tx.Message.Instructions) {
+		return 0, false
+	}
+
+	programIndex := int(tx.Message.Instructions[err.InstructionIndex].ProgramIDIndex)
+	if programIndex >= len(tx.Message.AccountKeys) || tx.Message.AccountKeys[programIndex] != solana.JupiterAggregatorV6ProgramID {
+		return 0, false
+	}
+
+	return solana.JupiterError(*err.Custom), true
+}
@@ -0,0 +1,253 @@
+package v6_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/solana/rpc"
+	"github.com/qiruos/jupiter/solana/rpc/rpctest"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Outcomes of the swap transaction scripted by swapUntilServers.
+const (
+	outcomeConfirmed = "confirmed"
+	outcomeSlippage  = "slippage"
+	outcomeFailed    = "failed"
+	outcomeExpired   = "expired"
+)
+
+// swapUntilServers returns fake Jupiter and RPC servers where the swap transaction of the n-th quote has the n-th outcome.
+func swapUntilServers(t *testing.T, user solana.PublicKey, outcomes ...string) (*jupitertest.Server, *rpctest.Server) {
+	t.Helper()
+
+	const lastValidBlockHeight = rpctest.DefaultBlockHeight + rpctest.BlockhashValidity
+
+	jup := jupitertest.NewServer()
+	t.Cleanup(jup.Close)
+	jup.SetSwapResponse(&v6.SwapResponse{
+		SwapTransaction:      unsignedSwapTransaction(t, user),
+		LastValidBlockHeight: lastValidBlockHeight,
+	})
+
+	outcome := func() string {
+		n := len(jup.Requests(jupitertest.EndpointQuote))
+		if n == 0 || n > len(outcomes) {
+			t.Errorf("unexpected attempt %d", n)
+			return outcomeFailed
+		}
+		return outcomes[n-1]
+	}
+
+	node := rpctest.NewServer()
+	t.Cleanup(node.Close)
+	node.Handle(rpctest.MethodGetBlockHeight, func(json.RawMessage) (interface{}, *rpc.Error) {
+		if outcome() == outcomeExpired {
+			return lastValidBlockHeight + 1, nil
+		}
+		return rpctest.DefaultBlockHeight, nil
+	})
+	node.Handle(rpctest.MethodGetSignatureStatuses, func(json.RawMessage) (interface{}, *rpc.Error) {
+		var status *rpc.SignatureStatus
+		switch outcome() {
+		case outcomeConfirmed:
+			status = &rpc.SignatureStatus{Slot: 1, ConfirmationStatus: rpc.CommitmentConfirmed}
+		case outcomeSlippage:
+			// The Jupiter route is the third instruction of the swap transaction.
+			status = &rpc.SignatureStatus{Slot: 1, Err: &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[2,{"Custom":6001}]}`)}}
+		case outcomeFailed:
+			status = &rpc.SignatureStatus{Slot: 1, Err: &rpc.TransactionError{Raw: json.RawMessage(`{"InstructionError":[0,{"Custom":6001}]}`)}}
+		}
+		return map[string]interface{}{"context": rpc.Context{Slot: 1}, "value": []*rpc.SignatureStatus{status}}, nil
+	})
+
+	return jup, node
+}
+
+func TestSwapUntil(t *testing.T) {
+	ctx := context.Background()
+	user, err := solana.NewKeypair()
+	require.NoError(t, err)
+
+	quoteParams := v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000, SlippageBps: 50}
+	swapParams := v6.SwapParams{UserPublicKey: user.PublicKey()}
+
+	t.Run("requotes with more slippage", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeSlippage, outcomeExpired, outcomeConfirmed)
+
+		var reported []v6.SwapAttempt
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{
+			Signer:         user,
+			Executor:       newExecutor(node),
+			MaxSlippageBps: 80,
+			OnAttempt:      func(a v6.SwapAttempt) { reported = append(reported, a) },
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Attempts, 3)
+		assert.Equal(t, report.Attempts, reported)
+
+		assert.EqualValues(t, 50, report.Attempts[0].SlippageBps)
+		assert.ErrorIs(t, report.Attempts[0].Err, solana.JupiterErrorSlippageToleranceExceeded)
+		assert.EqualValues(t, 80, report.Attempts[1].SlippageBps)
+		assert.Equal(t, v6.ExecutionExpired, report.Attempts[1].Result.Status)
+		assert.EqualValues(t, 80, report.Attempts[2].SlippageBps)
+		assert.NoError(t, report.Attempts[2].Err)
+
+		confirmed := report.Confirmed()
+		require.NotNil(t, confirmed)
+		assert.Equal(t, 3, confirmed.Attempt)
+		assert.False(t, confirmed.Signature.IsZero())
+
+		quotes := jup.Requests(jupitertest.EndpointQuote)
+		assert.Equal(t, "50", quotes[0].Query.Get("slippageBps"))
+		assert.Equal(t, "80", quotes[1].Query.Get("slippageBps"))
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeExpired, outcomeExpired)
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{
+			Signer:      user,
+			Executor:    newExecutor(node),
+			MaxAttempts: 2,
+		})
+		assert.ErrorIs(t, err, v6.ErrSwapAttemptsExhausted)
+		assert.Len(t, report.Attempts, 2)
+		assert.Nil(t, report.Confirmed())
+	})
+
+	t.Run("default slippage budget", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeSlippage, outcomeConfirmed)
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{Signer: user, Executor: newExecutor(node)})
+		require.NoError(t, err)
+		require.Len(t, report.Attempts, 2)
+		assert.EqualValues(t, 50, report.Attempts[0].SlippageBps)
+		assert.EqualValues(t, 100, report.Attempts[1].SlippageBps)
+		assert.NotNil(t, report.Confirmed())
+	})
+
+	t.Run("slippage budget exhausted", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeSlippage)
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{
+			Signer:         user,
+			Executor:       newExecutor(node),
+			MaxSlippageBps: 50,
+		})
+		assert.ErrorIs(t, err, v6.ErrSlippageBudgetExhausted)
+		assert.Len(t, report.Attempts, 1)
+	})
+
+	t.Run("other failures are not retried", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeFailed)
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{
+			Signer:         user,
+			Executor:       newExecutor(node),
+			MaxSlippageBps: 300,
+		})
+		assert.ErrorIs(t, err, v6.ErrSwapFailed)
+		require.Len(t, report.Attempts, 1)
+		assert.Equal(t, v6.ExecutionFailed, report.Attempts[0].Result.Status)
+	})
+
+	t.Run("price drift", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeExpired, outcomeExpired)
+		outAmounts := []string{"100000", "98500"}
+		jup.Handle(jupitertest.EndpointQuote, func(w http.ResponseWriter, r *http.Request) {
+			n := len(jup.Requests(jupitertest.EndpointQuote))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"inputMint":            wSolMint,
+				"outputMint":           usdcMint,
+				"inAmount":             "100000",
+				"outAmount":            outAmounts[n-1],
+				"otherAmountThreshold": "90000",
+				"swapMode":             v6.SwapModeExactIn,
+				"slippageBps":          50,
+			})
+		})
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{
+			Signer:           user,
+			Executor:         newExecutor(node),
+			MaxPriceDriftBps: 100,
+		})
+		assert.ErrorIs(t, err, v6.ErrPriceDriftExceeded)
+		require.Len(t, report.Attempts, 2)
+		assert.EqualValues(t, 0, report.Attempts[0].PriceDriftBps)
+		assert.EqualValues(t, 150, report.Attempts[1].PriceDriftBps)
+		assert.Nil(t, report.Attempts[1].Result)
+		assert.Len(t, node.Transactions(), 1)
+	})
+
+	t.Run("price drift of large amounts", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey(), outcomeExpired, outcomeConfirmed)
+		// The second quote is 99.99999999999999 bps worse, which float64 math rounds to 100.
+		outAmounts := []string{"18446744073709551615", "18262276632972456099"}
+		jup.Handle(jupitertest.EndpointQuote, func(w http.ResponseWriter, r *http.Request) {
+			n := len(jup.Requests(jupitertest.EndpointQuote))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"inputMint":            wSolMint,
+				"outputMint":           usdcMint,
+				"inAmount":             "100000",
+				"outAmount":            outAmounts[n-1],
+				"otherAmountThreshold": "0",
+				"swapMode":             v6.SwapModeExactIn,
+				"slippageBps":          50,
+			})
+		})
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{
+			Signer:           user,
+			Executor:         newExecutor(node),
+			MaxPriceDriftBps: 99,
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Attempts, 2)
+		assert.EqualValues(t, 99, report.Attempts[1].PriceDriftBps)
+	})
+
+	t.Run("quote error", func(t *testing.T) {
+		jup, node := swapUntilServers(t, user.PublicKey())
+		jup.InjectFault(jupitertest.EndpointQuote, jupitertest.Fault{StatusCode: http.StatusBadRequest, ErrorCode: v6.ErrorCodeCouldNotFindAnyRoute})
+
+		report, err := jup.Client().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{Signer: user, Executor: newExecutor(node)})
+		assert.ErrorIs(t, err, v6.ErrNoRoute)
+		require.Len(t, report.Attempts, 1)
+		assert.Nil(t, report.Attempts[0].Quote)
+	})
+
+	t.Run("requires a signer and an executor", func(t *testing.T) {
+		_, err := v6.NewClient().SwapUntil(ctx, quoteParams, swapParams, v6.SwapUntilOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestJupiterErrorOf(t *testing.T) {
+	tx, err := solana.TransactionFromBase64(jupitertest.DefaultSwapTransaction)
+	require.NoError(t, err)
+	custom := uint32(6001)
+
+	jupErr, ok := v6.JupiterErrorOf(tx, &rpc.TransactionError{Kind: "InstructionError", InstructionIndex: 2, Custom: &custom})
+	require.True(t, ok)
+	assert.Equal(t, solana.JupiterErrorSlippageToleranceExceeded, jupErr)
+	assert.True(t, errors.Is(jupErr, solana.JupiterErrorSlippageToleranceExceeded))
+
+	// The first instruction is a compute budget instruction.
+	_, ok = v6.JupiterErrorOf(tx, &rpc.TransactionError{Kind: "InstructionError", InstructionIndex: 0, Custom: &custom})
+	assert.False(t, ok)
+
+	_, ok = v6.JupiterErrorOf(tx, &rpc.TransactionError{Kind: "BlockhashNotFound", InstructionIndex: -1})
+	assert.False(t, ok)
+
+	_, ok = v6.JupiterErrorOf(tx, nil)
+	assert.False(t, ok)
+}
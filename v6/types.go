@@ -1,5 +1,13 @@
 package v6
 
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/qiruos/jupiter/solana"
+)
+
 // Predefined swap modes.
 const (
 	SwapModeExactIn  = "ExactIn"
@@ -48,9 +56,9 @@ const (
 
 // QuoteParams are the parameters for a quote request.
 type QuoteParams struct {
-	InputMint  string `url:"inputMint"`  // required. Input token mint address
-	OutputMint string `url:"outputMint"` // required. Output token mint address
-	Amount     uint64 `url:"amount"`     // required. The amount to swap, have to factor in the token decimals.
+	InputMint  solana.PublicKey `url:"inputMint"`  // required. Input token mint address
+	OutputMint solana.PublicKey `url:"outputMint"` // required. Output token mint address
+	Amount     uint64           `url:"amount"`     // required. The amount to swap, have to factor in the token decimals.
 
 	SlippageBps                   uint64   `url:"slippageBps,omitempty"`                   // Default is 50 unless autoSlippage is set to true. The slippage % in BPS. If the output token amount exceeds the slippage then the swap transaction will fail.
 	SwapMode                      string   `url:"swapMode,omitempty"`                      // (ExactIn or ExactOut) Defaults to ExactIn. ExactOut is for supporting use cases where you need an exact token amount, like payments. In this case the slippage is on the input token.
@@ -60,83 +68,219 @@ type QuoteParams struct {
 	OnlyDirectRoutes              bool     `url:"onlyDirectRoutes,omitempty"`              // Default is false. Direct Routes limits Jupiter routing to single hop routes only.
 	AsLegacyTransaction           bool     `url:"asLegacyTransaction,omitempty"`           // Default is false. Instead of using versioned transaction, this will use the legacy transaction.
 	PlatformFeeBps                uint64   `url:"platformFeeBps,omitempty"`                // If you want to charge the user a fee, you can specify the fee in BPS. Fee % is taken out of the output token.
-	maxAccounts                   uint64   `url:"maxAccounts,omitempty"`                   // Rough estimate of the max accounts to be used for the quote, so that you can compose with your own accounts
+	MaxAccounts                   uint64   `url:"maxAccounts,omitempty"`                   // Rough estimate of the max accounts to be used for the quote, so that you can compose with your own accounts
 	AutoSlippage                  bool     `url:"autoSlippage,omitempty"`                  // Default is false. By setting this to true, our API will suggest smart slippage info that you can use. computedAutoSlippage is the computed result, and slippageBps is what we suggest you to use. Additionally, you should check out maxAutoSlippageBps and autoSlippageCollisionUsdValue.
-	maxAutoSlippageBps            uint64   `url:"maxAutoSlippageBps,omitempty"`            // In conjunction with autoSlippage=true, the maximum slippageBps returned by the API will respect this value. It is recommended that you set something here.
-	autoSlippageCollisionUsdValue uint64   `url:"autoSlippageCollisionUsdValue,omitempty"` //If autoSlippage is set to true, our API will use a default 1000 USD value as way to calculate the slippage impact for the smart slippage. You can set a custom USD value using this parameter.
+	MaxAutoSlippageBps            uint64   `url:"maxAutoSlippageBps,omitempty"`            // In conjunction with autoSlippage=true, the maximum slippageBps returned by the API will respect this value. It is recommended that you set something here.
+	AutoSlippageCollisionUsdValue uint64   `url:"autoSlippageCollisionUsdValue,omitempty"` // If autoSlippage is set to true, our API will use a default 1000 USD value as way to calculate the slippage impact for the smart slippage. You can set a custom USD value using this parameter.
 }
 
 // QuoteResponse is the response from a quote request.
+//
+// A decoded QuoteResponse remembers the JSON it was decoded from. As long as its fields
+// are not modified, it is encoded back byte for byte, including fields unknown to this
+// package, so it can be passed as is to SwapParams.QuoteResponse.
 type QuoteResponse struct {
-	InputMint            string      `json:"inputMint"`
-	InAmount             string      `json:"inAmount"`
-	OutputMint           string      `json:"outputMint"`
-	OutAmount            string      `json:"outAmount"`
-	OtherAmountThreshold string      `json:"otherAmountThreshold"`
-	SwapMode             string      `json:"swapMode"`
-	SlippageBps          int         `json:"slippageBps"`
-	PlatformFee          interface{} `json:"platformFee"`
-	PriceImpactPct       string      `json:"priceImpactPct"`
-	RoutePlan            []struct {
-		SwapInfo struct {
-			AmmKey     string `json:"ammKey"`
-			Label      string `json:"label"`
-			InputMint  string `json:"inputMint"`
-			OutputMint string `json:"outputMint"`
-			InAmount   string `json:"inAmount"`
-			OutAmount  string `json:"outAmount"`
-			FeeAmount  string `json:"feeAmount"`
-			FeeMint    string `json:"feeMint"`
-		} `json:"swapInfo"`
-		Percent int `json:"percent"`
-	} `json:"routePlan"`
-	ContextSlot int     `json:"contextSlot"`
-	TimeTaken   float64 `json:"timeTaken"`
+	InputMint            solana.PublicKey `json:"inputMint"`
+	InAmount             uint64           `json:"inAmount,string"`
+	OutputMint           solana.PublicKey `json:"outputMint"`
+	OutAmount            uint64           `json:"outAmount,string"`
+	OtherAmountThreshold uint64           `json:"otherAmountThreshold,string"`
+	SwapMode             string           `json:"swapMode"`
+	SlippageBps          int              `json:"slippageBps"`
+	ComputedAutoSlippage int              `json:"computedAutoSlippage,omitempty"` // Only set if autoSlippage was requested. The computed slippage, before being capped by maxAutoSlippageBps.
+	PlatformFee          *PlatformFee     `json:"platformFee"`
+	PriceImpactPct       string           `json:"priceImpactPct"`
+	RoutePlan            []RoutePlanStep  `json:"routePlan"`
+	ContextSlot          int              `json:"contextSlot"`
+	TimeTaken            float64          `json:"timeTaken"`
+
+	raw []byte // JSON the response was decoded from
+}
+
+// PlatformFee is the platform fee charged on a quote, see QuoteParams.PlatformFeeBps.
+type PlatformFee struct {
+	Amount uint64 `json:"amount,string"`
+	FeeBps int    `json:"feeBps"`
+}
+
+// RoutePlanStep is a single leg of a quote route.
+type RoutePlanStep struct {
+	SwapInfo SwapInfo `json:"swapInfo"`
+	Percent  int      `json:"percent"` // share of the input amount routed through this leg
+}
+
+// SwapInfo describes the swap performed by a route leg.
+type SwapInfo struct {
+	AmmKey     solana.PublicKey `json:"ammKey"`
+	Label      string           `json:"label"`
+	InputMint  solana.PublicKey `json:"inputMint"`
+	OutputMint solana.PublicKey `json:"outputMint"`
+	InAmount   uint64           `json:"inAmount,string"`
+	OutAmount  uint64           `json:"outAmount,string"`
+	FeeAmount  uint64           `json:"feeAmount,string"`
+	FeeMint    solana.PublicKey `json:"feeMint"`
+}
+
+// quoteResponseFields has the fields of QuoteResponse without its JSON methods.
+type quoteResponseFields QuoteResponse
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (q *QuoteResponse) UnmarshalJSON(data []byte) error {
+	var f quoteResponseFields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*q = QuoteResponse(f)
+	q.raw = append([]byte(nil), data...)
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// It returns the JSON the response was decoded from if none of its fields were modified.
+func (q QuoteResponse) MarshalJSON() ([]byte, error) {
+	f := quoteResponseFields(q)
+	f.raw = nil
+
+	if len(q.raw) > 0 {
+		var orig quoteResponseFields
+		if err := json.Unmarshal(q.raw, &orig); err == nil && reflect.DeepEqual(orig, f) {
+			return q.raw, nil
+		}
+	}
+
+	return json.Marshal(f)
+}
+
+// Raw returns the JSON the response was decoded from, or nil if it was not decoded from JSON.
+func (q *QuoteResponse) Raw() json.RawMessage {
+	return q.raw
 }
 
 // SwapParams are the parameters for a swap request.
 type SwapParams struct {
-	QuoteResponse *QuoteResponse `json:"quoteResponse"` // required
-	UserPublicKey string         `json:"userPublicKey"` // required
-
-	WrapAndUnwrapSol              *bool  `json:"wrapAndUnwrapSol,omitempty"`              // Default is true. If true, will automatically wrap/unwrap SOL. If false, it will use wSOL token account. Will be ignored if destinationTokenAccount is set because the destinationTokenAccount may belong to a different user that we have no authority to close.
-	UseSharedAccounts             *bool  `json:"useSharedAccounts,omitempty"`             // Default is true. This enables the usage of shared program accountns. That means no intermediate token accounts or open orders accounts need to be created for the users. But it also means that the likelihood of hot accounts is higher.
-	FeeAccount                    string `json:"feeAccount,omitempty"`                    // Fee token account, same as the output token for ExactIn and as the input token for ExactOut, it is derived using the seeds = ["referral_ata", referral_account, mint] and the REFER4ZgmyYx9c6He5XfaTMiGfdLwRnkV4RPp9t9iF3 referral contract (only pass in if you set a feeBps and make sure that the feeAccount has been created).
-	TrackingAccount               string `json:"trackingAccount,omitempty"`               // Tracking account, this can be any public key that you can use to track the transactions, especially useful for integrator. Then, you can use the https://stats.jup.ag/tracking-account/:public-key/YYYY-MM-DD/HH endpoint to get all the swap transactions from this public key.
-	ComputeUnitPriceMicroLamports *int64 `json:"computeUnitPriceMicroLamports,omitempty"` // The compute unit price to prioritize the transaction, the additional fee will be computeUnitLimit (1400000) * computeUnitPriceMicroLamports. If auto is used, Jupiter will automatically set a priority fee and it will be capped at 5,000,000 lamports / 0.005 SOL.
-	PrioritizationFeeLamports     *int64 `json:"prioritizationFeeLamports,omitempty"`     // Prioritization fee lamports paid for the transaction in addition to the signatures fee. Mutually exclusive with compute_unit_price_micro_lamports. If auto is used, Jupiter will automatically set a priority fee and it will be capped at 5,000,000 lamports / 0.005 SOL. If autoMultiplier ({"autoMultiplier"}: 3}) is used, the priority fee will be a multplier on the auto fee. If jitoTipLamports ({"jitoTipLamports": 5000}) is used, a tip intruction will be included to Jito and no priority fee will be set.
-	AsLegacyTransaction           *bool  `json:"asLegacyTransaction,omitempty"`           // Default is false. Request a legacy transaction rather than the default versioned transaction, needs to be paired with a quote using asLegacyTransaction otherwise the transaction might be too large.
-	UseTokenLedger                *bool  `json:"useTokenLedger,omitempty"`                // Default is false. This is useful when the instruction before the swap has a transfer that increases the input token amount. Then, the swap will just use the difference between the token ledger token amount and post token amount.
-	DestinationTokenAccount       string `json:"destinationTokenAccount,omitempty"`       // Public key of the token account that will be used to receive the token out of the swap. If not provided, the user's ATA will be used. If provided, we assume that the token account is already initialized.
-	DynamicComputeUnitLimit       *bool  `json:"dynamicComputeUnitLimit,omitempty"`       // When enabled, it will do a swap simulation to get the compute unit used and set it in ComputeBudget's compute unit limit. This will increase latency slightly since there will be one extra RPC call to simulate this. Default is false.
-	SkipUserAccountsRpcCalls      *bool  `json:"skipUserAccountsRpcCalls,omitempty"`      // When enabled, it will not do any rpc calls check on user's accounts. Enable it only when you already setup all the accounts needed for the trasaction, like wrapping or unwrapping sol, destination account is already created.
+	QuoteResponse *QuoteResponse   `json:"quoteResponse"` // required
+	UserPublicKey solana.PublicKey `json:"userPublicKey"` // required
+
+	WrapAndUnwrapSol              *bool              `json:"wrapAndUnwrapSol,omitempty"`              // Default is true. If true, will automatically wrap/unwrap SOL. If false, it will use wSOL token account. Will be ignored if destinationTokenAccount is set because the destinationTokenAccount may belong to a different user that we have no authority to close.
+	UseSharedAccounts             *bool              `json:"useSharedAccounts,omitempty"`             // Default is true. This enables the usage of shared program accountns. That means no intermediate token accounts or open orders accounts need to be created for the users. But it also means that the likelihood of hot accounts is higher.
+	FeeAccount                    *solana.PublicKey  `json:"feeAccount,omitempty"`                    // Fee token account, same as the output token for ExactIn and as the input token for ExactOut, it is derived using the seeds = ["referral_ata", referral_account, mint] and the REFER4ZgmyYx9c6He5XfaTMiGfdLwRnkV4RPp9t9iF3 referral contract (only pass in if you set a feeBps and make sure that the feeAccount has been created).
+	TrackingAccount               *solana.PublicKey  `json:"trackingAccount,omitempty"`               // Tracking account, this can be any public key that you can use to track the transactions, especially useful for integrator. Then, you can use the https://stats.jup.ag/tracking-account/:public-key/YYYY-MM-DD/HH endpoint to get all the swap transactions from this public key.
+	ComputeUnitPriceMicroLamports *ComputeUnitPrice  `json:"computeUnitPriceMicroLamports,omitempty"` // The compute unit price to prioritize the transaction, the additional fee will be computeUnitLimit (1400000) * computeUnitPriceMicroLamports. If auto is used, Jupiter will automatically set a priority fee and it will be capped at 5,000,000 lamports / 0.005 SOL.
+	PrioritizationFeeLamports     *PrioritizationFee `json:"prioritizationFeeLamports,omitempty"`     // Prioritization fee lamports paid for the transaction in addition to the signatures fee. Mutually exclusive with compute_unit_price_micro_lamports. If auto is used, Jupiter will automatically set a priority fee and it will be capped at 5,000,000 lamports / 0.005 SOL. If autoMultiplier ({"autoMultiplier"}: 3}) is used, the priority fee will be a multplier on the auto fee. If jitoTipLamports ({"jitoTipLamports": 5000}) is used, a tip intruction will be included to Jito and no priority fee will be set. If priorityLevelWithMaxLamports is used, the fee will be estimated for the given priority level and capped at maxLamports.
+	AsLegacyTransaction           *bool              `json:"asLegacyTransaction,omitempty"`           // Default is false. Request a legacy transaction rather than the default versioned transaction, needs to be paired with a quote using asLegacyTransaction otherwise the transaction might be too large.
+	UseTokenLedger                *bool              `json:"useTokenLedger,omitempty"`                // Default is false. This is useful when the instruction before the swap has a transfer that increases the input token amount. Then, the swap will just use the difference between the token ledger token amount and post token amount.
+	DestinationTokenAccount       *solana.PublicKey  `json:"destinationTokenAccount,omitempty"`       // Public key of the token account that will be used to receive the token out of the swap. If not provided, the user's ATA will be used. If provided, we assume that the token account is already initialized.
+	DynamicComputeUnitLimit       *bool              `json:"dynamicComputeUnitLimit,omitempty"`       // When enabled, it will do a swap simulation to get the compute unit used and set it in ComputeBudget's compute unit limit. This will increase latency slightly since there will be one extra RPC call to simulate this. Default is false.
+	SkipUserAccountsRpcCalls      *bool              `json:"skipUserAccountsRpcCalls,omitempty"`      // When enabled, it will not do any rpc calls check on user's accounts. Enable it only when you already setup all the accounts needed for the trasaction, like wrapping or unwrapping sol, destination account is already created.
+	DynamicSlippage               *DynamicSlippage   `json:"dynamicSlippage,omitempty"`               // When set, Jupiter simulates the swap and sets the slippage of the transaction between minBps and maxBps, see SwapResponse.DynamicSlippageReport.
+}
+
+// DynamicSlippage bounds the slippage computed by Jupiter, see SwapParams.DynamicSlippage.
+type DynamicSlippage struct {
+	MinBps uint64 `json:"minBps"`
+	MaxBps uint64 `json:"maxBps"`
 }
 
 // SwapResponse is the response from a swap request.
+//
+// A decoded SwapResponse remembers the JSON it was decoded from, see Raw, so fields
+// unknown to this package are not lost.
 type SwapResponse struct {
-	SwapTransaction           string `json:"swapTransaction"` // base64 encoded transaction string
-	LastValidBlockHeight      int64  `json:"lastValidBlockHeight"`
-	PrioritizationFeeLamports int64  `json:"prioritizationFeeLamports"`
+	SwapTransaction           string                 `json:"swapTransaction"`      // base64 encoded transaction string
+	LastValidBlockHeight      int64                  `json:"lastValidBlockHeight"` // block height after which the transaction expires
+	PrioritizationFeeLamports int64                  `json:"prioritizationFeeLamports"`
+	ComputeUnitLimit          uint32                 `json:"computeUnitLimit,omitempty"`      // compute unit limit set in the transaction
+	PrioritizationType        *PrioritizationType    `json:"prioritizationType,omitempty"`    // how the prioritization fee is paid
+	DynamicSlippageReport     *DynamicSlippageReport `json:"dynamicSlippageReport,omitempty"` // Only set if dynamicSlippage was requested.
+	SimulationError           *SimulationError       `json:"simulationError,omitempty"`       // Only set if the simulation of the transaction by Jupiter failed.
+
+	raw []byte // JSON the response was decoded from
+}
+
+// PrioritizationType describes how the prioritization fee of a swap transaction is paid.
+// One of its fields is set.
+type PrioritizationType struct {
+	ComputeBudget *ComputeBudgetPrioritization `json:"computeBudget,omitempty"`
+	Jito          *JitoPrioritization          `json:"jito,omitempty"`
+}
+
+// ComputeBudgetPrioritization is a prioritization fee paid through the compute unit price.
+type ComputeBudgetPrioritization struct {
+	MicroLamports          uint64 `json:"microLamports"`          // compute unit price set in the transaction
+	EstimatedMicroLamports uint64 `json:"estimatedMicroLamports"` // compute unit price estimated by Jupiter
+}
+
+// JitoPrioritization is a prioritization fee paid as a Jito tip.
+type JitoPrioritization struct {
+	Lamports uint64 `json:"lamports"`
+}
+
+// DynamicSlippageReport reports the slippage Jupiter set in the transaction when dynamicSlippage was requested.
+type DynamicSlippageReport struct {
+	SlippageBps                  int     `json:"slippageBps"`                       // slippage set in the transaction
+	OtherAmount                  *uint64 `json:"otherAmount"`                       // simulated output amount for ExactIn, input amount for ExactOut
+	SimulatedIncurredSlippageBps *int    `json:"simulatedIncurredSlippageBps"`      // slippage incurred by the simulation, negative if better than quoted
+	AmplificationRatio           string  `json:"amplificationRatio,omitempty"`      // ratio applied to the simulated slippage
+	CategoryName                 string  `json:"categoryName,omitempty"`            // token category used to bound the slippage, e.g. "stable"
+	HeuristicMaxSlippageBps      int     `json:"heuristicMaxSlippageBps,omitempty"` // maximum slippage for the token category
+}
+
+// SimulationError is the error of the simulation of a swap transaction by Jupiter.
+type SimulationError struct {
+	ErrorCode string `json:"errorCode"`
+	Error     string `json:"error"`
+}
+
+// swapResponseFields has the fields of SwapResponse without its JSON methods.
+type swapResponseFields SwapResponse
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *SwapResponse) UnmarshalJSON(data []byte) error {
+	var f swapResponseFields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*r = SwapResponse(f)
+	r.raw = append([]byte(nil), data...)
+
+	return nil
+}
+
+// Raw returns the JSON the response was decoded from, or nil if it was not decoded from JSON.
+func (r *SwapResponse) Raw() json.RawMessage {
+	return r.raw
+}
+
+// Transaction decodes the swap transaction, so it can be inspected before being signed.
+func (r *SwapResponse) Transaction() (*solana.Transaction, error) {
+	tx, err := solana.TransactionFromBase64(r.SwapTransaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode swap transaction: %w", err)
+	}
+	return tx, nil
 }
 
 type SwapInstructionsResp struct {
-	TokenLedgerInstruction      interface{}   `json:"tokenLedgerInstruction"`
-	ComputeBudgetInstructions   []Instruction `json:"computeBudgetInstructions"`
-	SetupInstructions           []Instruction `json:"setupInstructions"`
-	SwapInstruction             Instruction   `json:"swapInstruction"`
-	CleanupInstruction          Instruction   `json:"cleanupInstruction"`
-	OtherInstructions           []Instruction `json:"otherInstructions"`
-	AddressLookupTableAddresses []string      `json:"addressLookupTableAddresses"`
-	PrioritizationFeeLamports   int64         `json:"prioritizationFeeLamports"`
+	TokenLedgerInstruction      *Instruction       `json:"tokenLedgerInstruction"` // set with SwapParams.UseTokenLedger, see TokenLedgerInstructions
+	ComputeBudgetInstructions   []Instruction      `json:"computeBudgetInstructions"`
+	SetupInstructions           []Instruction      `json:"setupInstructions"`
+	SwapInstruction             Instruction        `json:"swapInstruction"`
+	CleanupInstruction          *Instruction       `json:"cleanupInstruction"` // nil if no cleanup is needed
+	OtherInstructions           []Instruction      `json:"otherInstructions"`
+	AddressLookupTableAddresses []solana.PublicKey `json:"addressLookupTableAddresses"`
+	PrioritizationFeeLamports   int64              `json:"prioritizationFeeLamports"`
 }
 type Instruction struct {
-	ProgramId string    `json:"programId"`
-	Accounts  []Account `json:"accounts"`
-	Data      string    `json:"data"`
+	ProgramId solana.PublicKey `json:"programId"`
+	Accounts  []Account        `json:"accounts"`
+	Data      string           `json:"data"`
 }
 
 type Account struct {
-	Pubkey     string `json:"pubkey"`
-	IsSigner   bool   `json:"isSigner"`
-	IsWritable bool   `json:"isWritable"`
+	Pubkey     solana.PublicKey `json:"pubkey"`
+	IsSigner   bool             `json:"isSigner"`
+	IsWritable bool             `json:"isWritable"`
 }
@@ -0,0 +1,237 @@
+package v6_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/qiruos/jupiter/solana"
+	"github.com/qiruos/jupiter/utils"
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteParamsEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		params v6.QuoteParams
+		want   string
+	}{
+		{
+			name:   "required only",
+			params: v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000},
+			want:   "amount=100000&inputMint=" + wSolMint.String() + "&outputMint=" + usdcMint.String(),
+		},
+		{
+			name: "every field",
+			params: v6.QuoteParams{
+				InputMint:                     wSolMint,
+				OutputMint:                    usdcMint,
+				Amount:                        100000,
+				SlippageBps:                   30,
+				SwapMode:                      v6.SwapModeExactOut,
+				Dexes:                         []string{v6.DexRaydium, v6.DexOrcaV1},
+				ExcludeDexes:                  []string{v6.DexPhoenix},
+				RestrictIntermediateTokens:    true,
+				OnlyDirectRoutes:              true,
+				AsLegacyTransaction:           true,
+				PlatformFeeBps:                20,
+				MaxAccounts:                   54,
+				AutoSlippage:                  true,
+				MaxAutoSlippageBps:            300,
+				AutoSlippageCollisionUsdValue: 500,
+			},
+			want: "amount=100000" +
+				"&asLegacyTransaction=true" +
+				"&autoSlippage=true" +
+				"&autoSlippageCollisionUsdValue=500" +
+				"&dexes=Raydium&dexes=Orca+V1" +
+				"&excludeDexes=Phoenix" +
+				"&inputMint=" + wSolMint.String() +
+				"&maxAccounts=54" +
+				"&maxAutoSlippageBps=300" +
+				"&onlyDirectRoutes=true" +
+				"&outputMint=" + usdcMint.String() +
+				"&platformFeeBps=20" +
+				"&restrictIntermediateTokens=true" +
+				"&slippageBps=30" +
+				"&swapMode=ExactOut",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uv, err := utils.StructToUrlValues(tt.params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, uv.Encode())
+		})
+	}
+}
+
+func TestQuoteAutoSlippage(t *testing.T) {
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	c := srv.Client()
+
+	t.Run("computed slippage is surfaced", func(t *testing.T) {
+		quote, err := c.Quote(v6.QuoteParams{
+			InputMint:                     wSolMint,
+			OutputMint:                    usdcMint,
+			Amount:                        100000,
+			MaxAccounts:                   40,
+			AutoSlippage:                  true,
+			MaxAutoSlippageBps:            50,
+			AutoSlippageCollisionUsdValue: 500,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, jupitertest.DefaultComputedAutoSlippage, quote.ComputedAutoSlippage)
+		assert.Equal(t, 50, quote.SlippageBps)
+
+		req, ok := srv.LastRequest(jupitertest.EndpointQuote)
+		require.True(t, ok)
+		assert.Equal(t, "40", req.Query.Get("maxAccounts"))
+		assert.Equal(t, "50", req.Query.Get("maxAutoSlippageBps"))
+		assert.Equal(t, "500", req.Query.Get("autoSlippageCollisionUsdValue"))
+	})
+
+	t.Run("invalid params are rejected", func(t *testing.T) {
+		for _, params := range []v6.QuoteParams{
+			{InputMint: wSolMint, OutputMint: usdcMint, Amount: 1, MaxAutoSlippageBps: 300},
+			{InputMint: wSolMint, OutputMint: usdcMint, Amount: 1, AutoSlippageCollisionUsdValue: 500},
+			{InputMint: wSolMint, OutputMint: usdcMint, Amount: 1, AutoSlippage: true, MaxAutoSlippageBps: 10001},
+			{InputMint: wSolMint, OutputMint: usdcMint, Amount: 1, SlippageBps: 10001},
+		} {
+			_, err := c.Quote(params)
+			assert.Error(t, err)
+		}
+		assert.Len(t, srv.Requests(jupitertest.EndpointQuote), 1)
+	})
+}
+
+func TestQuoteResponseJSON(t *testing.T) {
+	const raw = `{"inputMint":"So11111111111111111111111111111111111111112","inAmount":"100000","outputMint":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","outAmount":"14951","otherAmountThreshold":"14877","swapMode":"ExactIn","slippageBps":50,"platformFee":{"amount":"30","feeBps":20},"priceImpactPct":"0.0001","routePlan":[{"swapInfo":{"ammKey":"BZtgQEyS6eXUXicYPHecYQ7PybqodXQMvkjUbP4R8mUU","label":"Meteora DLMM","inputMint":"So11111111111111111111111111111111111111112","outputMint":"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v","inAmount":"100000","outAmount":"14951","feeAmount":"10","feeMint":"So11111111111111111111111111111111111111112"},"percent":100}],"contextSlot":276544015,"timeTaken":0.005260881,"swapUsdValue":"0.0149"}`
+
+	var quote v6.QuoteResponse
+	require.NoError(t, json.Unmarshal([]byte(raw), &quote))
+
+	t.Run("typed fields", func(t *testing.T) {
+		assert.EqualValues(t, 100000, quote.InAmount)
+		assert.EqualValues(t, 14951, quote.OutAmount)
+		assert.EqualValues(t, 14877, quote.OtherAmountThreshold)
+		require.NotNil(t, quote.PlatformFee)
+		assert.EqualValues(t, 30, quote.PlatformFee.Amount)
+		assert.Equal(t, 20, quote.PlatformFee.FeeBps)
+		require.Len(t, quote.RoutePlan, 1)
+		assert.Equal(t, v6.DexMeteoraDLMM, quote.RoutePlan[0].SwapInfo.Label)
+		assert.EqualValues(t, 10, quote.RoutePlan[0].SwapInfo.FeeAmount)
+		assert.Equal(t, 100, quote.RoutePlan[0].Percent)
+		assert.JSONEq(t, raw, string(quote.Raw()))
+	})
+
+	t.Run("round-trips byte for byte", func(t *testing.T) {
+		buf, err := json.Marshal(v6.SwapParams{QuoteResponse: &quote, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		assert.Contains(t, string(buf), `"quoteResponse":`+raw+`,`)
+	})
+
+	t.Run("modified response is encoded from its fields", func(t *testing.T) {
+		modified := quote
+		modified.RoutePlan = append([]v6.RoutePlanStep(nil), quote.RoutePlan...)
+		modified.RoutePlan[0].Percent = 50
+
+		buf, err := json.Marshal(modified)
+		require.NoError(t, err)
+		assert.Contains(t, string(buf), `"percent":50`)
+		assert.Contains(t, string(buf), `"inAmount":"100000"`)
+		assert.NotContains(t, string(buf), "swapUsdValue")
+	})
+
+	t.Run("empty optional key", func(t *testing.T) {
+		raw := strings.Replace(raw, `"feeMint":"So11111111111111111111111111111111111111112"`, `"feeMint":""`, 1)
+
+		var quote v6.QuoteResponse
+		require.NoError(t, json.Unmarshal([]byte(raw), &quote))
+		assert.True(t, quote.RoutePlan[0].SwapInfo.FeeMint.IsZero())
+
+		// The response is passed back as received, not with the zero key encoded as the System Program.
+		buf, err := json.Marshal(v6.SwapParams{QuoteResponse: &quote, UserPublicKey: userPublicKey})
+		require.NoError(t, err)
+		assert.Contains(t, string(buf), `"feeMint":""`)
+	})
+
+	t.Run("response built in code", func(t *testing.T) {
+		buf, err := json.Marshal(v6.QuoteResponse{InAmount: 1, OutAmount: 2})
+		require.NoError(t, err)
+		assert.Contains(t, string(buf), `"inAmount":"1","outputMint":"11111111111111111111111111111111","outAmount":"2"`)
+		assert.Contains(t, string(buf), `"platformFee":null`)
+	})
+}
+
+func TestSwapParamsOptionalPublicKeys(t *testing.T) {
+	buf, err := json.Marshal(v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey})
+	require.NoError(t, err)
+	assert.NotContains(t, string(buf), "feeAccount")
+	assert.NotContains(t, string(buf), "destinationTokenAccount")
+
+	buf, err = json.Marshal(v6.SwapParams{UserPublicKey: userPublicKey, FeeAccount: &usdcMint})
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), `"userPublicKey":"`+userPublicKey.String()+`"`)
+	assert.Contains(t, string(buf), `"feeAccount":"`+usdcMint.String()+`"`)
+}
+
+func TestSwapResponseTransaction(t *testing.T) {
+	resp := v6.SwapResponse{SwapTransaction: jupitertest.DefaultSwapTransaction}
+
+	tx, err := resp.Transaction()
+	require.NoError(t, err)
+	assert.Equal(t, solana.MessageVersionV0, tx.Message.Version)
+	assert.Equal(t, []solana.PublicKey{userPublicKey}, tx.Message.Signers())
+	assert.True(t, tx.Signatures[0].IsZero())
+	assert.Equal(t, userPublicKey, tx.Message.AccountKeys[0])
+	require.Len(t, tx.Message.Instructions, 3)
+	program := tx.Message.AccountKeys[tx.Message.Instructions[2].ProgramIDIndex]
+	assert.Equal(t, solana.JupiterAggregatorV6ProgramID, program)
+
+	encoded, err := tx.Base64()
+	require.NoError(t, err)
+	assert.Equal(t, resp.SwapTransaction, encoded)
+
+	_, err = (&v6.SwapResponse{SwapTransaction: "tx"}).Transaction()
+	assert.Error(t, err)
+}
+
+func TestSwapResponseJSON(t *testing.T) {
+	const raw = `{"swapTransaction":"tx","lastValidBlockHeight":279632475,"prioritizationFeeLamports":9999,"computeUnitLimit":388876,"prioritizationType":{"computeBudget":{"microLamports":25715,"estimatedMicroLamports":785154}},"dynamicSlippageReport":{"slippageBps":50,"otherAmount":20612318,"simulatedIncurredSlippageBps":-18,"amplificationRatio":"1.5","categoryName":"lst","heuristicMaxSlippageBps":100},"simulationError":null,"addressesByLookupTableAddress":null}`
+
+	var swap v6.SwapResponse
+	require.NoError(t, json.Unmarshal([]byte(raw), &swap))
+
+	assert.EqualValues(t, 279632475, swap.LastValidBlockHeight)
+	assert.EqualValues(t, 9999, swap.PrioritizationFeeLamports)
+	assert.EqualValues(t, 388876, swap.ComputeUnitLimit)
+	require.NotNil(t, swap.PrioritizationType)
+	require.NotNil(t, swap.PrioritizationType.ComputeBudget)
+	assert.Nil(t, swap.PrioritizationType.Jito)
+	assert.EqualValues(t, 25715, swap.PrioritizationType.ComputeBudget.MicroLamports)
+	assert.EqualValues(t, 785154, swap.PrioritizationType.ComputeBudget.EstimatedMicroLamports)
+
+	report := swap.DynamicSlippageReport
+	require.NotNil(t, report)
+	assert.Equal(t, 50, report.SlippageBps)
+	require.NotNil(t, report.OtherAmount)
+	assert.EqualValues(t, 20612318, *report.OtherAmount)
+	require.NotNil(t, report.SimulatedIncurredSlippageBps)
+	assert.Equal(t, -18, *report.SimulatedIncurredSlippageBps)
+	assert.Equal(t, "lst", report.CategoryName)
+	assert.Equal(t, 100, report.HeuristicMaxSlippageBps)
+
+	assert.Nil(t, swap.SimulationError)
+	assert.JSONEq(t, raw, string(swap.Raw()))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"swapTransaction":"tx","simulationError":{"errorCode":"TRANSACTION_ERROR","error":"Slippage tolerance exceeded"}}`), &swap))
+	require.NotNil(t, swap.SimulationError)
+	assert.Equal(t, "TRANSACTION_ERROR", swap.SimulationError.ErrorCode)
+	assert.Nil(t, swap.DynamicSlippageReport)
+}
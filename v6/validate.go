@@ -0,0 +1,172 @@
+package v6
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidParams matches a *ValidationError with errors.Is.
+var ErrInvalidParams = errors.New("invalid params")
+
+// maxBps is 100% expressed in basis points.
+const maxBps = 10000
+
+// FieldError describes an invalid request parameter.
+type FieldError struct {
+	Field   string // JSON or query name of the parameter, e.g. "inputMint"
+	Message string
+}
+
+// Error implements error.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError is returned when request parameters fail client-side validation.
+// It lists every invalid parameter, not only the first one.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether target is ErrInvalidParams.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidParams
+}
+
+// Field returns the error of the given parameter, if any.
+func (e *ValidationError) Field(name string) (FieldError, bool) {
+	for _, f := range e.Fields {
+		if f.Field == name {
+			return f, true
+		}
+	}
+	return FieldError{}, false
+}
+
+// add records an invalid parameter.
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns e, or nil if no parameter is invalid.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks the parameters before they are sent to the API.
+// It returns a *ValidationError listing every invalid parameter, or nil.
+// The client calls it before every quote request unless disabled with WithValidation.
+func (p QuoteParams) Validate() error {
+	var v ValidationError
+
+	if p.InputMint.IsZero() {
+		v.add("inputMint", "is required")
+	}
+	if p.OutputMint.IsZero() {
+		v.add("outputMint", "is required")
+	}
+	if !p.InputMint.IsZero() && p.InputMint == p.OutputMint {
+		v.add("outputMint", "must differ from inputMint")
+	}
+	if p.Amount == 0 {
+		v.add("amount", "must be greater than 0")
+	}
+	if p.SwapMode != "" && p.SwapMode != SwapModeExactIn && p.SwapMode != SwapModeExactOut {
+		v.add("swapMode", "must be %s or %s, got %q", SwapModeExactIn, SwapModeExactOut, p.SwapMode)
+	}
+	if len(p.Dexes) > 0 && len(p.ExcludeDexes) > 0 {
+		v.add("excludeDexes", "cannot be combined with dexes")
+	}
+	if p.SlippageBps > maxBps {
+		v.add("slippageBps", "must be at most %d, got %d", maxBps, p.SlippageBps)
+	}
+	if p.PlatformFeeBps > maxBps {
+		v.add("platformFeeBps", "must be at most %d, got %d", maxBps, p.PlatformFeeBps)
+	}
+	if p.MaxAutoSlippageBps > maxBps {
+		v.add("maxAutoSlippageBps", "must be at most %d, got %d", maxBps, p.MaxAutoSlippageBps)
+	}
+	if !p.AutoSlippage && p.MaxAutoSlippageBps != 0 {
+		v.add("maxAutoSlippageBps", "requires autoSlippage to be set")
+	}
+	if !p.AutoSlippage && p.AutoSlippageCollisionUsdValue != 0 {
+		v.add("autoSlippageCollisionUsdValue", "requires autoSlippage to be set")
+	}
+
+	return v.err()
+}
+
+// Validate checks the parameters before they are sent to the API.
+// It returns a *ValidationError listing every invalid parameter, or nil.
+// The client calls it before every swap and swap instructions request unless disabled with WithValidation.
+func (p SwapParams) Validate() error {
+	var v ValidationError
+
+	if p.QuoteResponse == nil {
+		v.add("quoteResponse", "is required")
+	}
+	if p.UserPublicKey.IsZero() {
+		v.add("userPublicKey", "is required")
+	}
+	if p.PrioritizationFeeLamports != nil && p.ComputeUnitPriceMicroLamports != nil {
+		v.add("prioritizationFeeLamports", "cannot be combined with computeUnitPriceMicroLamports")
+	}
+	if p.PrioritizationFeeLamports != nil && p.PrioritizationFeeLamports.Kind() == 0 {
+		v.add("prioritizationFeeLamports", "must be created with one of the PrioritizationFee constructors")
+	}
+	if d := p.DynamicSlippage; d != nil {
+		if d.MaxBps > maxBps {
+			v.add("dynamicSlippage.maxBps", "must be at most %d, got %d", maxBps, d.MaxBps)
+		}
+		if d.MinBps > d.MaxBps {
+			v.add("dynamicSlippage.minBps", "must be at most maxBps, got %d > %d", d.MinBps, d.MaxBps)
+		}
+	}
+
+	return v.err()
+}
+
+// validateParams validates the params of the call unless validation is disabled with WithValidation.
+// It runs in the final handler, so params replaced by a middleware are validated too.
+func (c *Client) validateParams(call *Call) error {
+	if c.skipValidation {
+		return nil
+	}
+
+	var err error
+	switch p := call.Params.(type) {
+	case QuoteParams:
+		err = p.Validate()
+	case *QuoteParams:
+		if p != nil {
+			err = p.Validate()
+		}
+	case SwapParams:
+		err = p.Validate()
+	case *SwapParams:
+		if p != nil {
+			err = p.Validate()
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	if call.Operation == OperationQuote {
+		return fmt.Errorf("invalid quote params: %w", err)
+	}
+	return fmt.Errorf("invalid swap params: %w", err)
+}
@@ -0,0 +1,161 @@
+package v6_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qiruos/jupiter/v6"
+	"github.com/qiruos/jupiter/v6/jupitertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// invalidFields returns the fields reported by a *v6.ValidationError.
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var verr *v6.ValidationError
+	require.True(t, errors.As(err, &verr), "unexpected error: %v", err)
+	require.ErrorIs(t, err, v6.ErrInvalidParams)
+
+	fields := make([]string, len(verr.Fields))
+	for i, f := range verr.Fields {
+		fields[i] = f.Field
+	}
+	return fields
+}
+
+func TestQuoteParamsValidate(t *testing.T) {
+	valid := v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint, Amount: 100000}
+
+	tests := []struct {
+		name   string
+		modify func(p *v6.QuoteParams)
+		want   []string
+	}{
+		{name: "valid", modify: func(p *v6.QuoteParams) {}},
+		{name: "exact out", modify: func(p *v6.QuoteParams) { p.SwapMode = v6.SwapModeExactOut }},
+		{name: "missing mints", modify: func(p *v6.QuoteParams) { *p = v6.QuoteParams{Amount: 1} }, want: []string{"inputMint", "outputMint"}},
+		{name: "same mints", modify: func(p *v6.QuoteParams) { p.OutputMint = wSolMint }, want: []string{"outputMint"}},
+		{name: "zero amount", modify: func(p *v6.QuoteParams) { p.Amount = 0 }, want: []string{"amount"}},
+		{name: "unknown swap mode", modify: func(p *v6.QuoteParams) { p.SwapMode = "exactIn" }, want: []string{"swapMode"}},
+		{
+			name: "dexes and excluded dexes",
+			modify: func(p *v6.QuoteParams) {
+				p.Dexes = []string{v6.DexRaydium}
+				p.ExcludeDexes = []string{v6.DexPhoenix}
+			},
+			want: []string{"excludeDexes"},
+		},
+		{name: "slippage", modify: func(p *v6.QuoteParams) { p.SlippageBps = 10001 }, want: []string{"slippageBps"}},
+		{name: "platform fee", modify: func(p *v6.QuoteParams) { p.PlatformFeeBps = 10001 }, want: []string{"platformFeeBps"}},
+		{
+			name:   "auto slippage options without auto slippage",
+			modify: func(p *v6.QuoteParams) { p.MaxAutoSlippageBps, p.AutoSlippageCollisionUsdValue = 300, 500 },
+			want:   []string{"maxAutoSlippageBps", "autoSlippageCollisionUsdValue"},
+		},
+		{
+			name:   "max auto slippage",
+			modify: func(p *v6.QuoteParams) { p.AutoSlippage, p.MaxAutoSlippageBps = true, 10001 },
+			want:   []string{"maxAutoSlippageBps"},
+		},
+		{
+			name: "every error is reported",
+			modify: func(p *v6.QuoteParams) {
+				p.Amount = 0
+				p.SwapMode = "Both"
+				p.SlippageBps = 20000
+			},
+			want: []string{"amount", "swapMode", "slippageBps"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := valid
+			tt.modify(&params)
+			assert.Equal(t, tt.want, invalidFields(t, params.Validate()))
+		})
+	}
+}
+
+func TestSwapParamsValidate(t *testing.T) {
+	valid := v6.SwapParams{QuoteResponse: &v6.QuoteResponse{}, UserPublicKey: userPublicKey}
+
+	tests := []struct {
+		name   string
+		modify func(p *v6.SwapParams)
+		want   []string
+	}{
+		{name: "valid", modify: func(p *v6.SwapParams) {}},
+		{name: "prioritization fee", modify: func(p *v6.SwapParams) { p.PrioritizationFeeLamports = v6.PrioritizationFeeAuto() }},
+		{name: "compute unit price", modify: func(p *v6.SwapParams) { p.ComputeUnitPriceMicroLamports = v6.ComputeUnitPriceAuto() }},
+		{name: "missing", modify: func(p *v6.SwapParams) { *p = v6.SwapParams{} }, want: []string{"quoteResponse", "userPublicKey"}},
+		{
+			name: "prioritization fee and compute unit price",
+			modify: func(p *v6.SwapParams) {
+				p.PrioritizationFeeLamports = v6.PrioritizationFeeFixed(5000)
+				p.ComputeUnitPriceMicroLamports = v6.ComputeUnitPriceFixed(1000)
+			},
+			want: []string{"prioritizationFeeLamports"},
+		},
+		{name: "dynamic slippage", modify: func(p *v6.SwapParams) { p.DynamicSlippage = &v6.DynamicSlippage{MinBps: 50, MaxBps: 300} }},
+		{
+			name:   "inverted dynamic slippage",
+			modify: func(p *v6.SwapParams) { p.DynamicSlippage = &v6.DynamicSlippage{MinBps: 300, MaxBps: 50} },
+			want:   []string{"dynamicSlippage.minBps"},
+		},
+		{
+			name:   "dynamic slippage above 100%",
+			modify: func(p *v6.SwapParams) { p.DynamicSlippage = &v6.DynamicSlippage{MaxBps: 10001} },
+			want:   []string{"dynamicSlippage.maxBps"},
+		},
+		{
+			name:   "zero prioritization fee",
+			modify: func(p *v6.SwapParams) { p.PrioritizationFeeLamports = &v6.PrioritizationFee{} },
+			want:   []string{"prioritizationFeeLamports"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := valid
+			tt.modify(&params)
+			assert.Equal(t, tt.want, invalidFields(t, params.Validate()))
+		})
+	}
+}
+
+func TestClientValidation(t *testing.T) {
+	srv := jupitertest.NewServer()
+	defer srv.Close()
+
+	t.Run("invalid params fail before being sent", func(t *testing.T) {
+		srv.Reset()
+		c := srv.Client()
+
+		_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: wSolMint})
+		assert.Equal(t, []string{"outputMint", "amount"}, invalidFields(t, err))
+		assert.EqualError(t, err, "invalid quote params: outputMint: must differ from inputMint; amount: must be greater than 0")
+
+		_, err = c.Swap(v6.SwapParams{})
+		assert.Equal(t, []string{"quoteResponse", "userPublicKey"}, invalidFields(t, err))
+		_, err = c.SwapInstructions(v6.SwapParams{})
+		assert.Equal(t, []string{"quoteResponse", "userPublicKey"}, invalidFields(t, err))
+
+		assert.Empty(t, srv.Requests(jupitertest.EndpointQuote))
+		assert.Empty(t, srv.Requests(jupitertest.EndpointSwap))
+		assert.Empty(t, srv.Requests(jupitertest.EndpointSwapInstructions))
+	})
+
+	t.Run("can be disabled", func(t *testing.T) {
+		srv.Reset()
+		c := srv.Client(v6.WithValidation(false))
+
+		_, err := c.Quote(v6.QuoteParams{InputMint: wSolMint, OutputMint: usdcMint})
+		require.NoError(t, err)
+		assert.Len(t, srv.Requests(jupitertest.EndpointQuote), 1)
+	})
+}